@@ -4,7 +4,16 @@
 package cmd
 
 import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"regexp"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/juju/gnuflag"
 )
@@ -57,3 +66,522 @@ func (v *AppendStringsValue) Set(s string) error {
 func (v *AppendStringsValue) String() string {
 	return strings.Join(*v, ",")
 }
+
+// CountValue implements gnuflag.Value for a flag that counts the number of
+// times it is given, so that -v, -vv and -vvv give 1, 2 and 3. Like a
+// boolean flag it takes no argument, but --name=n may be used to set the
+// count directly.
+type CountValue int
+
+var _ gnuflag.Value = (*CountValue)(nil)
+
+// NewCountValue is used to create the type passed into the gnuflag.FlagSet Var function.
+// f.Var(cmd.NewCountValue(defaultValue, &someMember), "name", "help")
+func NewCountValue(defaultValue int, target *int) *CountValue {
+	*target = defaultValue
+	return (*CountValue)(target)
+}
+
+// Implements gnuflag.Value Set.
+func (v *CountValue) Set(s string) error {
+	switch s {
+	case "true":
+		*v++
+		return nil
+	case "false":
+		*v = 0
+		return nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 0 {
+		return errors.New("expected a non-negative count")
+	}
+	*v = CountValue(n)
+	return nil
+}
+
+// Implements gnuflag.Value String.
+func (v *CountValue) String() string {
+	return strconv.Itoa(int(*v))
+}
+
+// IsBoolFlag tells gnuflag that the flag does not take an argument.
+func (v *CountValue) IsBoolFlag() bool {
+	return true
+}
+
+// ListValue implements gnuflag.Value for a list of strings. Unlike
+// StringsValue, values are parsed as a line of CSV, so elements containing
+// commas or spaces can be given by quoting them:
+//
+//	--tags 'a,"b,c",d'
+//
+// An empty value yields an empty list, and empty elements are rejected
+// unless AllowEmpty is set. Errors in individual elements are reported
+// with the element's position in the list, counting from 1.
+type ListValue struct {
+	// Target receives the list.
+	Target *[]string
+
+	// Accumulate causes each use of the flag to add to the list, as
+	// AppendStringsValue does, rather than replacing it. In either case
+	// the first use of the flag replaces the default value.
+	Accumulate bool
+
+	// Trim causes leading and trailing white space to be removed
+	// from each element.
+	Trim bool
+
+	// Unique causes repeated elements to be dropped, keeping the first.
+	Unique bool
+
+	// AllowEmpty allows empty elements in the list.
+	AllowEmpty bool
+
+	// Validators are called for each element of the list; the first
+	// error returned causes the value to be rejected.
+	Validators []ListValidator
+
+	set bool
+}
+
+// ListValidator checks an element of a ListValue.
+type ListValidator func(string) error
+
+var _ gnuflag.Value = (*ListValue)(nil)
+
+// NewListValue is used to create the type passed into the gnuflag.FlagSet Var function.
+// f.Var(cmd.NewListValue(defaultValue, &someMember), "name", "help")
+func NewListValue(defaultValue []string, target *[]string) *ListValue {
+	*target = defaultValue
+	return &ListValue{Target: target}
+}
+
+// Implements gnuflag.Value Set.
+func (v *ListValue) Set(s string) error {
+	if v.Trim {
+		s = strings.TrimSpace(s)
+	}
+	r := csv.NewReader(strings.NewReader(s))
+	r.FieldsPerRecord = -1
+	r.TrimLeadingSpace = v.Trim
+	var elements []string
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			if parseErr, ok := err.(*csv.ParseError); ok {
+				err = parseErr.Err
+			}
+			return fmt.Errorf("cannot parse list: %v", err)
+		}
+		elements = append(elements, record...)
+	}
+
+	var list []string
+	if v.Accumulate && v.set {
+		list = *v.Target
+	}
+	offset := len(list)
+	for i, element := range elements {
+		if v.Trim {
+			element = strings.TrimSpace(element)
+		}
+		if element == "" && !v.AllowEmpty {
+			return fmt.Errorf("element %d is empty", offset+i+1)
+		}
+		for _, validate := range v.Validators {
+			if err := validate(element); err != nil {
+				return fmt.Errorf("element %d (%q): %v", offset+i+1, element, err)
+			}
+		}
+		if v.Unique && containsString(list, element) {
+			continue
+		}
+		list = append(list, element)
+	}
+	if list == nil {
+		list = []string{}
+	}
+	*v.Target = list
+	v.set = true
+	return nil
+}
+
+// Implements gnuflag.Value String.
+func (v *ListValue) String() string {
+	if len(*v.Target) == 0 {
+		return ""
+	}
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	w.Write(*v.Target)
+	w.Flush()
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+// MatchesRegexp returns a ListValidator that requires each element to
+// match the given regular expression in its entirety.
+func MatchesRegexp(pattern string) ListValidator {
+	re := regexp.MustCompile("^(?:" + pattern + ")$")
+	return func(s string) error {
+		if !re.MatchString(s) {
+			return fmt.Errorf("does not match %q", pattern)
+		}
+		return nil
+	}
+}
+
+// OneOf returns a ListValidator that requires each element to be one
+// of the given choices.
+func OneOf(choices ...string) ListValidator {
+	return func(s string) error {
+		if !containsString(choices, s) {
+			return errors.New("expected one of: " + strings.Join(choices, ", "))
+		}
+		return nil
+	}
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// EnumValue implements gnuflag.Value for a flag whose value must be one of a
+// fixed set of choices.
+type EnumValue struct {
+	// Target receives the chosen value.
+	Target *string
+
+	// Choices holds the allowed values, in the order in which they
+	// are documented.
+	Choices []string
+
+	// Descriptions optionally holds a short description of each
+	// choice, which is included in the flag's documentation.
+	Descriptions map[string]string
+
+	// IgnoreCase allows the choices to be matched case-insensitively.
+	// The value stored in Target is always spelled as it is in Choices.
+	IgnoreCase bool
+}
+
+var _ gnuflag.Value = (*EnumValue)(nil)
+
+// NewEnumValue is used to create the type passed into the gnuflag.FlagSet Var function.
+// f.Var(cmd.NewEnumValue("default", &someMember, choices), "name", "help")
+// The default value must be one of the choices, or empty; NewEnumValue
+// panics otherwise.
+func NewEnumValue(defaultValue string, target *string, choices []string) *EnumValue {
+	v := &EnumValue{
+		Target:  target,
+		Choices: choices,
+	}
+	*target = ""
+	if defaultValue != "" {
+		if err := v.Set(defaultValue); err != nil {
+			panic(err)
+		}
+	}
+	return v
+}
+
+// Implements gnuflag.Value Set.
+func (v *EnumValue) Set(s string) error {
+	for _, choice := range v.Choices {
+		if s == choice || v.IgnoreCase && strings.EqualFold(s, choice) {
+			*v.Target = choice
+			return nil
+		}
+	}
+	// Note that gnuflag will prepend the bad argument to the error message, so
+	// we don't need to restate it here.
+	return errors.New("expected one of: " + strings.Join(v.Choices, ", "))
+}
+
+// Implements gnuflag.Value String.
+func (v *EnumValue) String() string {
+	return *v.Target
+}
+
+// Doc returns the given usage text for the flag with the choices appended,
+// along with their descriptions if there are any.
+func (v *EnumValue) Doc(usage string) string {
+	doc := fmt.Sprintf("%s (%s)", usage, strings.Join(v.Choices, "|"))
+	if len(v.Descriptions) == 0 {
+		return doc
+	}
+	longest := 0
+	for _, choice := range v.Choices {
+		if width := displayWidth(choice); width > longest {
+			longest = width
+		}
+	}
+	for _, choice := range v.Choices {
+		if description := v.Descriptions[choice]; description != "" {
+			doc += fmt.Sprintf("\n      %s  %s", padRight(choice, longest), description)
+		}
+	}
+	return doc
+}
+
+// Complete returns the choices that start with prefix, for use by shell
+// completion.
+func (v *EnumValue) Complete(prefix string) []string {
+	var result []string
+	for _, choice := range v.Choices {
+		if strings.HasPrefix(choice, prefix) || v.IgnoreCase && strings.HasPrefix(strings.ToLower(choice), strings.ToLower(prefix)) {
+			result = append(result, choice)
+		}
+	}
+	return result
+}
+
+// DurationValue implements gnuflag.Value for a time.Duration. In addition to
+// the units understood by time.ParseDuration, it accepts "d" for days, so
+// that "2d", "1h30m" and "90s" are all valid.
+type DurationValue struct {
+	// Target receives the parsed duration.
+	Target *time.Duration
+
+	// Min and Max, if non-zero, bound the accepted durations.
+	Min, Max time.Duration
+}
+
+var _ gnuflag.Value = (*DurationValue)(nil)
+
+// NewDurationValue is used to create the type passed into the gnuflag.FlagSet Var function.
+// f.Var(cmd.NewDurationValue(defaultValue, &someMember), "name", "help")
+func NewDurationValue(defaultValue time.Duration, target *time.Duration) *DurationValue {
+	*target = defaultValue
+	return &DurationValue{Target: target}
+}
+
+// Implements gnuflag.Value Set.
+func (v *DurationValue) Set(s string) error {
+	d, err := ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	if v.Min != 0 && d < v.Min {
+		return fmt.Errorf("duration must be at least %s", FormatDuration(v.Min))
+	}
+	if v.Max != 0 && d > v.Max {
+		return fmt.Errorf("duration must be at most %s", FormatDuration(v.Max))
+	}
+	*v.Target = d
+	return nil
+}
+
+// Implements gnuflag.Value String.
+func (v *DurationValue) String() string {
+	return FormatDuration(*v.Target)
+}
+
+var daysRE = regexp.MustCompile(`([0-9]*(?:\.[0-9]*)?)d`)
+
+// ParseDuration parses a duration as time.ParseDuration does, but also
+// accepts "d" as a unit meaning 24 hours.
+func ParseDuration(s string) (time.Duration, error) {
+	var err error
+	expanded := daysRE.ReplaceAllStringFunc(s, func(days string) string {
+		n, parseErr := strconv.ParseFloat(strings.TrimSuffix(days, "d"), 64)
+		if parseErr != nil {
+			err = fmt.Errorf("invalid duration %q", s)
+			return days
+		}
+		return strconv.FormatFloat(n*24, 'f', -1, 64) + "h"
+	})
+	if err != nil {
+		return 0, err
+	}
+	return time.ParseDuration(expanded)
+}
+
+// FormatDuration returns a canonical representation of d, omitting any
+// trailing zero units, so that 90 minutes is rendered as "1h30m" rather
+// than "1h30m0s".
+func FormatDuration(d time.Duration) string {
+	s := d.String()
+	if strings.HasSuffix(s, "m0s") {
+		s = s[:len(s)-2]
+	}
+	if strings.HasSuffix(s, "h0m") {
+		s = s[:len(s)-2]
+	}
+	return s
+}
+
+// ByteSizeValue implements gnuflag.Value for a size in bytes. Sizes are
+// given as a number with an optional unit. The units K, M, G, T and P, with
+// or without a trailing "iB", are powers of 1024, while KB, MB, GB, TB and
+// PB are powers of 1000. A plain number, or one with a "B" suffix, is a
+// number of bytes. Fractional values such as "1.5GiB" are accepted as long
+// as they amount to a whole number of bytes.
+type ByteSizeValue struct {
+	// Target receives the size in bytes.
+	Target *uint64
+
+	// Min and Max, if non-zero, bound the accepted sizes.
+	Min, Max uint64
+}
+
+var _ gnuflag.Value = (*ByteSizeValue)(nil)
+
+// NewByteSizeValue is used to create the type passed into the gnuflag.FlagSet Var function.
+// f.Var(cmd.NewByteSizeValue(defaultValue, &someMember), "name", "help")
+func NewByteSizeValue(defaultValue uint64, target *uint64) *ByteSizeValue {
+	*target = defaultValue
+	return &ByteSizeValue{Target: target}
+}
+
+// Implements gnuflag.Value Set.
+func (v *ByteSizeValue) Set(s string) error {
+	size, err := ParseByteSize(s)
+	if err != nil {
+		return err
+	}
+	if v.Min != 0 && size < v.Min {
+		return fmt.Errorf("size must be at least %s", FormatByteSize(v.Min))
+	}
+	if v.Max != 0 && size > v.Max {
+		return fmt.Errorf("size must be at most %s", FormatByteSize(v.Max))
+	}
+	*v.Target = size
+	return nil
+}
+
+// Implements gnuflag.Value String.
+func (v *ByteSizeValue) String() string {
+	return FormatByteSize(*v.Target)
+}
+
+var byteSizeUnits = []struct {
+	suffix string
+	size   float64
+}{
+	{"kb", 1e3},
+	{"mb", 1e6},
+	{"gb", 1e9},
+	{"tb", 1e12},
+	{"pb", 1e15},
+	{"kib", 1 << 10},
+	{"mib", 1 << 20},
+	{"gib", 1 << 30},
+	{"tib", 1 << 40},
+	{"pib", 1 << 50},
+	{"k", 1 << 10},
+	{"m", 1 << 20},
+	{"g", 1 << 30},
+	{"t", 1 << 40},
+	{"p", 1 << 50},
+	{"b", 1},
+}
+
+// ParseByteSize parses a size in bytes as described for ByteSizeValue.
+func ParseByteSize(s string) (uint64, error) {
+	number := strings.ToLower(strings.TrimSpace(s))
+	multiplier := 1.0
+	for _, unit := range byteSizeUnits {
+		if strings.HasSuffix(number, unit.suffix) {
+			number = strings.TrimSpace(strings.TrimSuffix(number, unit.suffix))
+			multiplier = unit.size
+			break
+		}
+	}
+	n, err := strconv.ParseFloat(number, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid size %q", s)
+	}
+	size := n * multiplier
+	if size != math.Trunc(size) || size >= 1<<64 {
+		return 0, fmt.Errorf("invalid size %q", s)
+	}
+	return uint64(size), nil
+}
+
+// FormatByteSize returns a canonical representation of size, using the
+// largest binary unit that represents it exactly.
+func FormatByteSize(size uint64) string {
+	if size == 0 {
+		return "0B"
+	}
+	units := []string{"B", "KiB", "MiB", "GiB", "TiB", "PiB"}
+	unit := 0
+	for unit < len(units)-1 && size%1024 == 0 {
+		size /= 1024
+		unit++
+	}
+	return strconv.FormatUint(size, 10) + units[unit]
+}
+
+// TimeValue implements gnuflag.Value for a point in time. It accepts
+// timestamps in RFC3339 format, "now", or a duration relative to now
+// such as "-2h" or "+1d".
+type TimeValue struct {
+	// Target receives the parsed time.
+	Target *time.Time
+
+	// Min and Max, if non-zero, bound the accepted times.
+	Min, Max time.Time
+
+	// Now, if set, is used in place of time.Now to evaluate
+	// "now" and relative times.
+	Now func() time.Time
+}
+
+var _ gnuflag.Value = (*TimeValue)(nil)
+
+// NewTimeValue is used to create the type passed into the gnuflag.FlagSet Var function.
+// f.Var(cmd.NewTimeValue(defaultValue, &someMember), "name", "help")
+func NewTimeValue(defaultValue time.Time, target *time.Time) *TimeValue {
+	*target = defaultValue
+	return &TimeValue{Target: target}
+}
+
+// Implements gnuflag.Value Set.
+func (v *TimeValue) Set(s string) error {
+	now := time.Now
+	if v.Now != nil {
+		now = v.Now
+	}
+	var t time.Time
+	switch {
+	case s == "now":
+		t = now()
+	case strings.HasPrefix(s, "-") || strings.HasPrefix(s, "+"):
+		d, err := ParseDuration(s)
+		if err != nil {
+			return err
+		}
+		t = now().Add(d)
+	default:
+		var err error
+		if t, err = time.Parse(time.RFC3339, s); err != nil {
+			return fmt.Errorf("expected RFC3339 timestamp, \"now\" or relative duration")
+		}
+	}
+	if !v.Min.IsZero() && t.Before(v.Min) {
+		return fmt.Errorf("time must not be before %s", v.Min.Format(time.RFC3339))
+	}
+	if !v.Max.IsZero() && t.After(v.Max) {
+		return fmt.Errorf("time must not be after %s", v.Max.Format(time.RFC3339))
+	}
+	*v.Target = t
+	return nil
+}
+
+// Implements gnuflag.Value String.
+func (v *TimeValue) String() string {
+	if v.Target.IsZero() {
+		return ""
+	}
+	return v.Target.Format(time.RFC3339)
+}
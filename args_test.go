@@ -4,11 +4,14 @@
 package cmd_test
 
 import (
+	"bytes"
 	"fmt"
 	"io/ioutil"
+	"time"
 
 	"github.com/juju/gnuflag"
 	"github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
 	gc "gopkg.in/check.v1"
 
 	"github.com/juju/cmd"
@@ -172,3 +175,444 @@ func (*ArgsSuite) TestAppendStringsUsage(c *gc.C) {
 		c.Check(value, gc.DeepEquals, test.expectedValue)
 	}
 }
+
+func (*ArgsSuite) TestEnumValue(c *gc.C) {
+	for i, test := range []struct {
+		message       string
+		defaultValue  string
+		ignoreCase    bool
+		args          []string
+		expectedValue string
+		expectedError string
+	}{{
+		message: "no default and no arg",
+	}, {
+		message:       "default value and not set by args",
+		defaultValue:  "bar",
+		expectedValue: "bar",
+	}, {
+		message:       "default value and set by args",
+		defaultValue:  "bar",
+		args:          []string{"--value", "baz"},
+		expectedValue: "baz",
+	}, {
+		message:       "case mismatch",
+		args:          []string{"--value", "BAZ"},
+		expectedError: `invalid value "BAZ" for flag --value: expected one of: foo, bar, baz`,
+	}, {
+		message:       "case insensitive",
+		ignoreCase:    true,
+		args:          []string{"--value", "BAZ"},
+		expectedValue: "baz",
+	}, {
+		message:       "invalid choice",
+		args:          []string{"--value", "qux"},
+		expectedError: `invalid value "qux" for flag --value: expected one of: foo, bar, baz`,
+	}} {
+		c.Log(fmt.Sprintf("%v: %s", i, test.message))
+		f := gnuflag.NewFlagSet("test", gnuflag.ContinueOnError)
+		f.SetOutput(ioutil.Discard)
+		var value string
+		enum := cmd.NewEnumValue(test.defaultValue, &value, []string{"foo", "bar", "baz"})
+		enum.IgnoreCase = test.ignoreCase
+		f.Var(enum, "value", "help")
+		err := f.Parse(false, test.args)
+		if test.expectedError != "" {
+			c.Check(err, gc.ErrorMatches, test.expectedError)
+			continue
+		}
+		c.Check(err, gc.IsNil)
+		c.Check(value, gc.Equals, test.expectedValue)
+	}
+}
+
+func (*ArgsSuite) TestEnumValueInvalidDefault(c *gc.C) {
+	var value string
+	c.Assert(func() {
+		cmd.NewEnumValue("qux", &value, []string{"foo", "bar"})
+	}, gc.PanicMatches, "expected one of: foo, bar")
+}
+
+func (*ArgsSuite) TestEnumValueDoc(c *gc.C) {
+	var value string
+	enum := cmd.NewEnumValue("", &value, []string{"tabular", "json"})
+	c.Check(enum.Doc("Specify output format"), gc.Equals, "Specify output format (tabular|json)")
+
+	enum.Descriptions = map[string]string{
+		"tabular": "human readable table",
+		"json":    "machine readable",
+	}
+	c.Check(enum.Doc("Specify output format"), gc.Equals, `
+Specify output format (tabular|json)
+      tabular  human readable table
+      json     machine readable`[1:])
+}
+
+func (*ArgsSuite) TestEnumValueComplete(c *gc.C) {
+	var value string
+	enum := cmd.NewEnumValue("", &value, []string{"yaml", "json", "jsonl"})
+	c.Check(enum.Complete(""), gc.DeepEquals, []string{"yaml", "json", "jsonl"})
+	c.Check(enum.Complete("js"), gc.DeepEquals, []string{"json", "jsonl"})
+	c.Check(enum.Complete("JS"), gc.HasLen, 0)
+	enum.IgnoreCase = true
+	c.Check(enum.Complete("JS"), gc.DeepEquals, []string{"json", "jsonl"})
+}
+
+func (*ArgsSuite) TestDurationValue(c *gc.C) {
+	for i, test := range []struct {
+		arg      string
+		expected time.Duration
+		err      string
+	}{{
+		arg:      "90s",
+		expected: 90 * time.Second,
+	}, {
+		arg:      "1h30m",
+		expected: 90 * time.Minute,
+	}, {
+		arg:      "2d",
+		expected: 48 * time.Hour,
+	}, {
+		arg:      "1.5d",
+		expected: 36 * time.Hour,
+	}, {
+		arg:      "1d12h",
+		expected: 36 * time.Hour,
+	}, {
+		arg: "90",
+		err: `time: missing unit in duration "?90"?`,
+	}, {
+		arg: "1s",
+		err: "duration must be at least 1m",
+	}, {
+		arg: "3d",
+		err: "duration must be at most 48h",
+	}} {
+		c.Logf("%d: %s", i, test.arg)
+		var d time.Duration
+		value := cmd.NewDurationValue(0, &d)
+		value.Min = time.Minute
+		value.Max = 48 * time.Hour
+		err := value.Set(test.arg)
+		if test.err != "" {
+			c.Check(err, gc.ErrorMatches, test.err)
+			continue
+		}
+		c.Check(err, gc.IsNil)
+		c.Check(d, gc.Equals, test.expected)
+	}
+}
+
+func (*ArgsSuite) TestDurationValueString(c *gc.C) {
+	for _, test := range []struct {
+		d        time.Duration
+		expected string
+	}{
+		{0, "0s"},
+		{10 * time.Second, "10s"},
+		{90 * time.Second, "1m30s"},
+		{90 * time.Minute, "1h30m"},
+		{48 * time.Hour, "48h"},
+		{time.Hour + time.Second, "1h0m1s"},
+	} {
+		var d time.Duration
+		c.Check(cmd.NewDurationValue(test.d, &d).String(), gc.Equals, test.expected)
+	}
+}
+
+func (*ArgsSuite) TestByteSizeValue(c *gc.C) {
+	for i, test := range []struct {
+		arg      string
+		expected uint64
+		err      string
+	}{{
+		arg:      "1024",
+		expected: 1024,
+	}, {
+		arg:      "512M",
+		expected: 512 << 20,
+	}, {
+		arg:      "512MiB",
+		expected: 512 << 20,
+	}, {
+		arg:      "1.5GiB",
+		expected: 3 << 29,
+	}, {
+		arg:      "2kb",
+		expected: 2000,
+	}, {
+		arg:      "10 MB",
+		expected: 10e6,
+	}, {
+		arg: "1.5B",
+		err: `invalid size "1.5B"`,
+	}, {
+		arg: "lots",
+		err: `invalid size "lots"`,
+	}, {
+		arg: "-1K",
+		err: `invalid size "-1K"`,
+	}, {
+		arg: "10B",
+		err: "size must be at least 1KiB",
+	}, {
+		arg: "2T",
+		err: "size must be at most 1TiB",
+	}} {
+		c.Logf("%d: %s", i, test.arg)
+		var size uint64
+		value := cmd.NewByteSizeValue(0, &size)
+		value.Min = 1 << 10
+		value.Max = 1 << 40
+		err := value.Set(test.arg)
+		if test.err != "" {
+			c.Check(err, gc.ErrorMatches, test.err)
+			continue
+		}
+		c.Check(err, gc.IsNil)
+		c.Check(size, gc.Equals, test.expected)
+	}
+}
+
+func (*ArgsSuite) TestParseByteSizeOverflow(c *gc.C) {
+	for _, arg := range []string{"18446744073709551616", "16384PiB", "1e30", "inf"} {
+		_, err := cmd.ParseByteSize(arg)
+		c.Check(err, gc.ErrorMatches, `invalid size ".*"`, gc.Commentf("%s", arg))
+	}
+	size, err := cmd.ParseByteSize("8192PiB")
+	c.Assert(err, gc.IsNil)
+	c.Assert(size, gc.Equals, uint64(1<<63))
+}
+
+func (*ArgsSuite) TestByteSizeValueString(c *gc.C) {
+	for _, test := range []struct {
+		size     uint64
+		expected string
+	}{
+		{0, "0B"},
+		{1000, "1000B"},
+		{1024, "1KiB"},
+		{3 << 29, "1536MiB"},
+		{1 << 30, "1GiB"},
+	} {
+		var size uint64
+		c.Check(cmd.NewByteSizeValue(test.size, &size).String(), gc.Equals, test.expected)
+	}
+}
+
+func (*ArgsSuite) TestTimeValue(c *gc.C) {
+	now := time.Date(2018, 6, 1, 12, 0, 0, 0, time.UTC)
+	for i, test := range []struct {
+		arg      string
+		expected time.Time
+		err      string
+	}{{
+		arg:      "now",
+		expected: now,
+	}, {
+		arg:      "-2h",
+		expected: now.Add(-2 * time.Hour),
+	}, {
+		arg:      "+1d",
+		expected: now.Add(24 * time.Hour),
+	}, {
+		arg:      "2018-05-31T00:00:00Z",
+		expected: time.Date(2018, 5, 31, 0, 0, 0, 0, time.UTC),
+	}, {
+		arg: "yesterday",
+		err: `expected RFC3339 timestamp, "now" or relative duration`,
+	}, {
+		arg: "-32d",
+		err: "time must not be before 2018-05-01T00:00:00Z",
+	}, {
+		arg: "+2d",
+		err: "time must not be after 2018-06-02T12:00:00Z",
+	}} {
+		c.Logf("%d: %s", i, test.arg)
+		var t time.Time
+		value := cmd.NewTimeValue(time.Time{}, &t)
+		value.Now = func() time.Time { return now }
+		value.Min = time.Date(2018, 5, 1, 0, 0, 0, 0, time.UTC)
+		value.Max = now.Add(24 * time.Hour)
+		err := value.Set(test.arg)
+		if test.err != "" {
+			c.Check(err, gc.ErrorMatches, test.err)
+			continue
+		}
+		c.Check(err, gc.IsNil)
+		c.Check(t.Equal(test.expected), jc.IsTrue)
+	}
+}
+
+func (*ArgsSuite) TestTimeValueDefaults(c *gc.C) {
+	f := gnuflag.NewFlagSet("test", gnuflag.ContinueOnError)
+	var since, until time.Time
+	var wait time.Duration
+	var size uint64
+	f.Var(cmd.NewTimeValue(time.Time{}, &since), "since", "start")
+	f.Var(cmd.NewTimeValue(time.Date(2018, 6, 1, 0, 0, 0, 0, time.UTC), &until), "until", "end")
+	f.Var(cmd.NewDurationValue(90*time.Minute, &wait), "wait", "wait")
+	f.Var(cmd.NewByteSizeValue(1<<30, &size), "size", "size")
+	var buf bytes.Buffer
+	f.SetOutput(&buf)
+	f.PrintDefaults()
+	c.Assert(buf.String(), gc.Equals, `
+--since (= )
+    start
+--size (= 1GiB)
+    size
+--until (= 2018-06-01T00:00:00Z)
+    end
+--wait (= 1h30m)
+    wait
+`[1:])
+}
+
+func (*ArgsSuite) TestListValue(c *gc.C) {
+	for i, test := range []struct {
+		message      string
+		defaultValue []string
+		setup        func(*cmd.ListValue)
+		args         []string
+		expected     []string
+		err          string
+	}{{
+		message:      "default value and not set by args",
+		defaultValue: []string{"foo"},
+		expected:     []string{"foo"},
+	}, {
+		message:  "empty value",
+		args:     []string{"--value", ""},
+		expected: []string{},
+	}, {
+		message:  "quoted values",
+		args:     []string{"--value", `a,"b,c","d ""e"""`},
+		expected: []string{"a", "b,c", `d "e"`},
+	}, {
+		message:  "spaces preserved",
+		args:     []string{"--value", "a b, c"},
+		expected: []string{"a b", " c"},
+	}, {
+		message:  "trimmed",
+		setup:    func(v *cmd.ListValue) { v.Trim = true },
+		args:     []string{"--value", ` a , "b,c" `},
+		expected: []string{"a", "b,c"},
+	}, {
+		message: "empty element",
+		args:    []string{"--value", "a,,b"},
+		err:     `invalid value "a,,b" for flag --value: element 2 is empty`,
+	}, {
+		message:  "empty element allowed",
+		setup:    func(v *cmd.ListValue) { v.AllowEmpty = true },
+		args:     []string{"--value", "a,,b"},
+		expected: []string{"a", "", "b"},
+	}, {
+		message: "bad quoting",
+		args:    []string{"--value", `a,"b`},
+		err:     `invalid value "a,\\"b" for flag --value: cannot parse list: .*`,
+	}, {
+		message:      "replace",
+		defaultValue: []string{"foo"},
+		args:         []string{"--value", "a,b", "--value", "c"},
+		expected:     []string{"c"},
+	}, {
+		message:      "accumulate",
+		defaultValue: []string{"foo"},
+		setup:        func(v *cmd.ListValue) { v.Accumulate = true },
+		args:         []string{"--value", "a,b", "--value", "c"},
+		expected:     []string{"a", "b", "c"},
+	}, {
+		message:  "unique",
+		setup:    func(v *cmd.ListValue) { v.Unique = true; v.Accumulate = true },
+		args:     []string{"--value", "a,b,a", "--value", "b,c"},
+		expected: []string{"a", "b", "c"},
+	}, {
+		message: "regexp validator",
+		setup: func(v *cmd.ListValue) {
+			v.Validators = []cmd.ListValidator{cmd.MatchesRegexp("[a-z]+")}
+		},
+		args: []string{"--value", "abc,d3f"},
+		err:  `invalid value "abc,d3f" for flag --value: element 2 \("d3f"\): does not match "\[a-z\]\+"`,
+	}, {
+		message: "enum validator counts accumulated elements",
+		setup: func(v *cmd.ListValue) {
+			v.Accumulate = true
+			v.Validators = []cmd.ListValidator{cmd.OneOf("x", "y")}
+		},
+		args: []string{"--value", "x,y", "--value", "z"},
+		err:  `invalid value "z" for flag --value: element 3 \("z"\): expected one of: x, y`,
+	}, {
+		message: "custom validator",
+		setup: func(v *cmd.ListValue) {
+			v.Validators = []cmd.ListValidator{func(s string) error {
+				if len(s) > 2 {
+					return fmt.Errorf("too long")
+				}
+				return nil
+			}}
+		},
+		args: []string{"--value", "ab,abc"},
+		err:  `invalid value "ab,abc" for flag --value: element 2 \("abc"\): too long`,
+	}} {
+		c.Log(fmt.Sprintf("%v: %s", i, test.message))
+		f := gnuflag.NewFlagSet("test", gnuflag.ContinueOnError)
+		f.SetOutput(ioutil.Discard)
+		var value []string
+		list := cmd.NewListValue(test.defaultValue, &value)
+		if test.setup != nil {
+			test.setup(list)
+		}
+		f.Var(list, "value", "help")
+		err := f.Parse(false, test.args)
+		if test.err != "" {
+			c.Check(err, gc.ErrorMatches, test.err)
+			continue
+		}
+		c.Check(err, gc.IsNil)
+		c.Check(value, gc.DeepEquals, test.expected)
+	}
+}
+
+func (*ArgsSuite) TestListValueString(c *gc.C) {
+	var value []string
+	list := cmd.NewListValue(nil, &value)
+	c.Check(list.String(), gc.Equals, "")
+	value = []string{"a", "b,c", `d "e"`}
+	c.Check(list.String(), gc.Equals, `a,"b,c","d ""e"""`)
+
+	var roundTrip []string
+	err := cmd.NewListValue(nil, &roundTrip).Set(list.String())
+	c.Check(err, gc.IsNil)
+	c.Check(roundTrip, gc.DeepEquals, value)
+}
+
+func (*ArgsSuite) TestCountValue(c *gc.C) {
+	for i, test := range []struct {
+		args     []string
+		expected int
+		err      string
+	}{
+		{args: nil, expected: 0},
+		{args: []string{"-v"}, expected: 1},
+		{args: []string{"-vvv"}, expected: 3},
+		{args: []string{"-v", "--verbose", "-vv"}, expected: 4},
+		{args: []string{"--verbose=2"}, expected: 2},
+		{args: []string{"-vv", "--verbose=false"}, expected: 0},
+		{args: []string{"--verbose=lots"}, err: `invalid value "lots" for flag --verbose: expected a non-negative count`},
+	} {
+		c.Logf("%d: %q", i, test.args)
+		f := gnuflag.NewFlagSet("test", gnuflag.ContinueOnError)
+		f.SetOutput(ioutil.Discard)
+		var count int
+		value := cmd.NewCountValue(0, &count)
+		f.Var(value, "v", "help")
+		f.Var(value, "verbose", "help")
+		err := f.Parse(false, test.args)
+		if test.err != "" {
+			c.Check(err, gc.ErrorMatches, test.err)
+			continue
+		}
+		c.Check(err, gc.IsNil)
+		c.Check(count, gc.Equals, test.expected)
+	}
+}
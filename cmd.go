@@ -12,7 +12,9 @@ import (
 	"os"
 	"os/signal"
 	"path/filepath"
+	"runtime/debug"
 	"strings"
+	"sync"
 
 	"github.com/juju/ansiterm"
 	"github.com/juju/gnuflag"
@@ -108,13 +110,27 @@ func (c *CommandBase) AllowInterspersedFlags() bool {
 // should interpret file names relative to Dir (see AbsPath below), and print
 // output and errors to Stdout and Stderr respectively.
 type Context struct {
-	Dir     string
-	Env     map[string]string
-	Stdin   io.Reader
-	Stdout  io.Writer
-	Stderr  io.Writer
-	quiet   bool
-	verbose bool
+	Dir       string
+	Env       map[string]string
+	Stdin     io.Reader
+	Stdout    io.Writer
+	Stderr    io.Writer
+	quiet     bool
+	verbosity int
+	assumeYes bool
+	noPrompt  bool
+
+	// pipedSecrets records whether ReadSecret may read from a stdin
+	// that is not a terminal.
+	pipedSecrets bool
+
+	// stdinFlag records the flag that reads from Stdin, if any.
+	stdinFlag *gnuflag.Flag
+
+	// progress draws progress bars and other items on Stderr. It is
+	// created once, by progressDisplay.
+	progress     *progressDisplay
+	progressOnce sync.Once
 }
 
 // Quiet reports whether the command is in "quiet" mode. When
@@ -159,10 +175,17 @@ func (ctx *Context) Warningf(format string, params ...interface{}) {
 	logger.Logf(loggo.WARNING, format, params...)
 }
 
+// Verbosity returns the level of verbosity requested on the command line:
+// 0 if no verbose flag was given, 1 for -v or --verbose, 2 for -vv, and so
+// on.
+func (ctx *Context) Verbosity() int {
+	return ctx.verbosity
+}
+
 // Verbosef will write the formatted string to Stderr if the verbose is true,
 // and to the logger if not.
 func (ctx *Context) Verbosef(format string, params ...interface{}) {
-	if ctx.verbose {
+	if ctx.verbosity > 0 {
 		ctx.write(format, params...)
 	} else {
 		//Here we use the Loggo.logger method `Logf` as opposed to
@@ -211,6 +234,16 @@ func (ctx *Context) AbsPath(path string) string {
 	return filepath.Join(ctx.Dir, path)
 }
 
+// claimStdin records that flag reads from Stdin, returning an error
+// if another flag has already claimed it.
+func (ctx *Context) claimStdin(flag *gnuflag.Flag) error {
+	if ctx.stdinFlag != nil && !containsValue([]gnuflag.Value{ctx.stdinFlag.Value}, flag.Value) {
+		return fmt.Errorf("%s and %s cannot both read from stdin", flagName(ctx.stdinFlag.Name), flagName(flag.Name))
+	}
+	ctx.stdinFlag = flag
+	return nil
+}
+
 // GetStdin satisfies environs.BootstrapContext
 func (ctx *Context) GetStdin() io.Reader {
 	return ctx.Stdin
@@ -253,6 +286,29 @@ type Info struct {
 	// Aliases are other names for the Command.
 	Aliases []string
 
+	// Category is the heading, such as "Deployment", under which the
+	// Command is listed in help. Commands without a category are listed
+	// after the others.
+	Category string
+
+	// Hidden causes the Command to be left out of the list of commands
+	// in help. It can still be run, and "help <name>" still works.
+	Hidden bool
+
+	// Examples holds example uses of the Command, which are shown in
+	// its help. The examples can be checked against the command tree
+	// with cmdtesting.CheckExamples.
+	Examples []Example
+
+	// SeeAlso holds the names of related commands and help topics,
+	// which are listed in the Command's help. A name is resolved from
+	// the outermost SuperCommand, so a command in a nested SuperCommand
+	// is named by its path, as in "cloud add". The outermost
+	// SuperCommand logs a warning when it is first initialized if a
+	// name does not exist; SuperCommand.CheckSeeAlso makes the same
+	// check for tests.
+	SeeAlso []string
+
 	// FlagKnownAs allows different projects to customise what their flags are
 	// known as, e.g. 'flag', 'option', 'item'. All error/log messages
 	// will use that name when referring to an individual items/flags in this command.
@@ -265,6 +321,17 @@ type Info struct {
 	ShowSuperFlags []string
 }
 
+// Example describes an example use of a Command.
+type Example struct {
+	// Description explains what the example does.
+	Description string
+
+	// Command holds the full command line, starting with the name of
+	// the outermost command, as in "juju deploy mysql --to 2". Words
+	// may be quoted as in the shell.
+	Command string
+}
+
 // Help renders i's content, along with documentation for any
 // flags defined in f.
 func (i *Info) Help(f *gnuflag.FlagSet) []byte {
@@ -275,10 +342,28 @@ func (i *Info) Help(f *gnuflag.FlagSet) []byte {
 // flags defined in both command and its super command flag sets.
 // Only super command flags defined in i.ShowSuperFlags are displayed, if found.
 func (i *Info) HelpWithSuperFlags(superF *gnuflag.FlagSet, f *gnuflag.FlagSet) []byte {
+	return i.helpWithSuperFlags(superF, f, helpOptions{})
+}
+
+// helpOptions holds options for rendering help.
+type helpOptions struct {
+	// showDeprecated causes deprecated flags to be listed in a section
+	// of their own.
+	showDeprecated bool
+
+	// width holds the width to which text is wrapped, or 0 if text
+	// is left as written.
+	width int
+}
+
+// helpWithSuperFlags implements HelpWithSuperFlags.
+func (i *Info) helpWithSuperFlags(superF *gnuflag.FlagSet, f *gnuflag.FlagSet, opts helpOptions) []byte {
 	buf := &bytes.Buffer{}
 	fmt.Fprintf(buf, "Usage: %s", i.Name)
 	hasOptions := false
-	f.VisitAll(func(f *gnuflag.Flag) { hasOptions = true })
+	f.VisitAll(func(flag *gnuflag.Flag) {
+		hasOptions = hasOptions || isShownFlag(flag, opts.showDeprecated)
+	})
 	if hasOptions {
 		fmt.Fprintf(buf, " [%vs]", f.FlagKnownAs)
 	}
@@ -287,11 +372,10 @@ func (i *Info) HelpWithSuperFlags(superF *gnuflag.FlagSet, f *gnuflag.FlagSet) [
 	}
 	fmt.Fprintf(buf, "\n")
 	if i.Purpose != "" {
-		fmt.Fprintf(buf, "\nSummary:\n%s\n", strings.TrimSpace(i.Purpose))
+		fmt.Fprintf(buf, "\nSummary:\n%s\n", reflow(strings.TrimSpace(i.Purpose), opts.width))
 	}
 	hasSuperFlags := false
 	if superF != nil && len(i.ShowSuperFlags) != 0 {
-		filteredSuperF := gnuflag.NewFlagSetWithFlagKnownAs("", gnuflag.ContinueOnError, superF.FlagKnownAs)
 		contains := func(one string) bool {
 			for _, a := range i.ShowSuperFlags {
 				if strings.ToLower(one) == strings.ToLower(a) {
@@ -300,17 +384,15 @@ func (i *Info) HelpWithSuperFlags(superF *gnuflag.FlagSet, f *gnuflag.FlagSet) [
 			}
 			return false
 		}
+		wanted := func(flag *gnuflag.Flag) bool {
+			return contains(flag.Name) && isShownFlag(flag, false)
+		}
 		superF.VisitAll(func(flag *gnuflag.Flag) {
-			if contains(flag.Name) {
-				hasSuperFlags = true
-				filteredSuperF.Var(flag.Value, flag.Name, flag.Usage)
-			}
+			hasSuperFlags = hasSuperFlags || wanted(flag)
 		})
 		if hasSuperFlags {
-			fmt.Fprintf(buf, "\nGlobal %vs:\n", strings.Title(filteredSuperF.FlagKnownAs))
-			filteredSuperF.SetOutput(buf)
-			filteredSuperF.PrintDefaults()
-			filteredSuperF.SetOutput(ioutil.Discard)
+			fmt.Fprintf(buf, "\nGlobal %vs:\n", strings.Title(superF.FlagKnownAs))
+			writeFlagDefaults(buf, superF, wanted, nil)
 		}
 	}
 
@@ -320,17 +402,37 @@ func (i *Info) HelpWithSuperFlags(superF *gnuflag.FlagSet, f *gnuflag.FlagSet) [
 		} else {
 			fmt.Fprintf(buf, "\n%vs:\n", strings.Title(f.FlagKnownAs))
 		}
-		f.SetOutput(buf)
-		f.PrintDefaults()
+		printFlagDefaults(buf, f)
+	}
+	if opts.showDeprecated {
+		if deprecated := deprecatedFlagDefaults(f); deprecated != "" {
+			fmt.Fprintf(buf, "\nDeprecated %vs:\n%s", strings.Title(f.FlagKnownAs), deprecated)
+		}
 	}
 	f.SetOutput(ioutil.Discard)
 	if i.Doc != "" {
 		fmt.Fprintf(buf, "\nDetails:\n")
-		fmt.Fprintf(buf, "%s\n", strings.TrimSpace(i.Doc))
+		fmt.Fprintf(buf, "%s\n", reflow(strings.TrimSpace(i.Doc), opts.width))
+	}
+	if len(i.Examples) > 0 {
+		fmt.Fprintf(buf, "\nExamples:\n")
+		for _, example := range i.Examples {
+			fmt.Fprintf(buf, "\n")
+			if example.Description != "" {
+				fmt.Fprintf(buf, "%s\n", reflow(strings.TrimSpace(example.Description), opts.width))
+			}
+			fmt.Fprintf(buf, "    %s\n", example.Command)
+		}
 	}
 	if len(i.Aliases) > 0 {
 		fmt.Fprintf(buf, "\nAliases: %s\n", strings.Join(i.Aliases, ", "))
 	}
+	if len(i.SeeAlso) > 0 {
+		fmt.Fprintf(buf, "\nSee also:\n")
+		for _, name := range i.SeeAlso {
+			fmt.Fprintf(buf, "    %s\n", name)
+		}
+	}
 	return buf.Bytes()
 }
 
@@ -343,7 +445,7 @@ func handleCommandError(c Command, ctx *Context, err error, f *gnuflag.FlagSet)
 	case nil:
 		return 0, false
 	case gnuflag.ErrHelp:
-		ctx.Stdout.Write(c.Info().Help(f))
+		ctx.Stdout.Write(c.Info().helpWithSuperFlags(nil, f, helpOptions{width: ctx.helpWidth()}))
 		return 0, true
 	case ErrSilent:
 		return 2, true
@@ -363,10 +465,16 @@ func FlagAlias(c Command, akaDefault string) string {
 
 // Main runs the given Command in the supplied Context with the given
 // arguments, which should not include the command name. It returns a code
-// suitable for passing to os.Exit.
-func Main(c Command, ctx *Context, args []string) int {
+// suitable for passing to os.Exit. If the command panics, Main writes a
+// crash report and returns PanicExitCode.
+func Main(c Command, ctx *Context, args []string) (rc int) {
 	f := gnuflag.NewFlagSetWithFlagKnownAs(c.Info().Name, gnuflag.ContinueOnError, FlagAlias(c, "flag"))
 	f.SetOutput(ioutil.Discard)
+	defer func() {
+		if v := recover(); v != nil {
+			rc = reportCrash(c, ctx, f, args, &panicError{value: v, stack: debug.Stack()})
+		}
+	}()
 	c.SetFlags(f)
 	if rc, done := handleCommandError(c, ctx, f.Parse(c.AllowInterspersedFlags(), args), f); done {
 		return rc
@@ -376,7 +484,10 @@ func Main(c Command, ctx *Context, args []string) int {
 	if rc, done := handleCommandError(c, ctx, c.Init(f.Args()), f); done {
 		return rc
 	}
-	if err := c.Run(ctx); err != nil {
+	if rc, done := handleCommandError(c, ctx, ResolveFlags(ctx, f), f); done {
+		return rc
+	}
+	if err := Run(c, ctx); err != nil {
 		if IsRcPassthroughError(err) {
 			return err.(*RcPassthroughError).Code
 		}
@@ -5,7 +5,9 @@ package cmdtesting
 
 import (
 	"bytes"
+	"io"
 	"io/ioutil"
+	"strings"
 
 	"github.com/juju/gnuflag"
 	gc "gopkg.in/check.v1"
@@ -24,13 +26,18 @@ func NewFlagSet() *gnuflag.FlagSet {
 // InitCommand will create a new flag set, and call the Command's SetFlags and
 // Init methods with the appropriate args.
 func InitCommand(c cmd.Command, args []string) error {
+	_, err := initCommand(c, args)
+	return err
+}
+
+func initCommand(c cmd.Command, args []string) (*gnuflag.FlagSet, error) {
 	f := gnuflag.NewFlagSetWithFlagKnownAs(c.Info().Name, gnuflag.ContinueOnError, cmd.FlagAlias(c, "flag"))
 	f.SetOutput(ioutil.Discard)
 	c.SetFlags(f)
 	if err := f.Parse(c.AllowInterspersedFlags(), args); err != nil {
-		return err
+		return nil, err
 	}
-	return c.Init(f.Args())
+	return f, c.Init(f.Args())
 }
 
 // Context creates a simple command execution context with the current
@@ -83,11 +90,17 @@ func RunCommandInDir(c *gc.C, com cmd.Command, args []string, dir string) (*cmd.
 }
 
 func runCommand(ctx *cmd.Context, com cmd.Command, args []string) (*cmd.Context, error) {
-	if err := InitCommand(com, args); err != nil {
+	f, err := initCommand(com, args)
+	if err == nil {
+		// As in cmd.Main, flag values are resolved between
+		// Init and Run.
+		err = cmd.ResolveFlags(ctx, f)
+	}
+	if err != nil {
 		cmd.WriteError(ctx.Stderr, err)
 		return ctx, err
 	}
-	return ctx, com.Run(ctx)
+	return ctx, cmd.Run(com, ctx)
 }
 
 // TestInit checks that a command initialises correctly with the given set of
@@ -111,3 +124,49 @@ func HelpText(command cmd.Command, name string) string {
 	buff.Write(info.Help(f))
 	return buff.String()
 }
+
+// ContextWithPrompter returns a command execution context, like Context,
+// that reads user input from p and writes prompts to it, so that a
+// Prompter or SeqPrompter can drive the Context's prompting methods.
+func ContextWithPrompter(c *gc.C, p io.ReadWriter) *cmd.Context {
+	ctx := Context(c)
+	ctx.Stdin = terminalReader{p}
+	ctx.Stderr = p
+	return ctx
+}
+
+// ContextWithSecrets returns a command execution context, like Context,
+// whose Stdin supplies each of the given secrets in turn as a line of
+// input, for commands that call ReadSecret.
+func ContextWithSecrets(c *gc.C, secrets ...string) *cmd.Context {
+	ctx := Context(c)
+	ctx.Stdin = terminalReader{bytes.NewBufferString(strings.Join(secrets, "\n") + "\n")}
+	return ctx
+}
+
+// terminalReader wraps a reader so that the Context prompting methods
+// treat it as an interactive terminal.
+type terminalReader struct {
+	io.Reader
+}
+
+// IsTerminal reports that the reader is a terminal.
+func (terminalReader) IsTerminal() bool {
+	return true
+}
+
+// CheckSecretsNotWritten checks that none of the given secrets was written
+// to the Stdout or Stderr of ctx, which should have been created by this
+// package, and reports whether the check succeeded.
+func CheckSecretsNotWritten(c *gc.C, ctx *cmd.Context, secrets ...string) bool {
+	ok := true
+	for _, secret := range secrets {
+		for name, output := range map[string]string{"stdout": Stdout(ctx), "stderr": Stderr(ctx)} {
+			if strings.Contains(output, secret) {
+				c.Errorf("secret %q written to %s", secret, name)
+				ok = false
+			}
+		}
+	}
+	return ok
+}
@@ -0,0 +1,100 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENSE file for details.
+
+package cmdtesting
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/cmd"
+)
+
+// CheckExamples checks that every example in the help of the command
+// returned by newCommand, and of all its subcommands, gets through flag
+// parsing and Init. newCommand is called to create a fresh command tree
+// for each example, so that examples do not affect one another.
+func CheckExamples(c *gc.C, newCommand func() cmd.Command) {
+	for _, example := range commandExamples(newCommand()) {
+		if err := checkExample(newCommand(), example.Command); err != nil {
+			c.Errorf("example %q: %v", example.Command, err)
+		}
+	}
+}
+
+// commandExamples returns the examples of command and its subcommands.
+func commandExamples(command cmd.Command) []cmd.Example {
+	examples := command.Info().Examples
+	if super, ok := command.(*cmd.SuperCommand); ok {
+		super.VisitCommands(func(_ []string, command cmd.Command) {
+			examples = append(examples, command.Info().Examples...)
+		})
+	}
+	return examples
+}
+
+// checkExample parses the given command line and initializes command
+// with it.
+func checkExample(command cmd.Command, commandLine string) error {
+	args, err := splitCommandLine(commandLine)
+	if err != nil {
+		return err
+	}
+	name := command.Info().Name
+	if len(args) == 0 || args[0] != name {
+		return fmt.Errorf("example does not start with %q", name)
+	}
+	_, err = initCommand(command, args[1:])
+	return err
+}
+
+// splitCommandLine splits s into words as the shell would, removing any
+// quotes and backslash escapes.
+func splitCommandLine(s string) ([]string, error) {
+	var words []string
+	var word bytes.Buffer
+	inWord := false
+	var quote rune
+	escaped := false
+	for _, r := range s {
+		switch {
+		case escaped:
+			if quote == '"' && !strings.ContainsRune(`"\$`+"`", r) {
+				word.WriteRune('\\')
+			}
+			word.WriteRune(r)
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped = true
+			inWord = true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote = r
+			inWord = true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+	if quote != 0 || escaped {
+		return nil, fmt.Errorf("unterminated quote or escape in %q", s)
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words, nil
+}
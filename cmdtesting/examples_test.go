@@ -0,0 +1,125 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENSE file for details.
+
+package cmdtesting_test
+
+import (
+	"errors"
+
+	"github.com/juju/gnuflag"
+	"github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/cmd"
+	"github.com/juju/cmd/cmdtesting"
+)
+
+type examplesSuite struct {
+	testing.IsolationSuite
+}
+
+var _ = gc.Suite(&examplesSuite{})
+
+func (*examplesSuite) TestSplitCommandLine(c *gc.C) {
+	for i, test := range []struct {
+		line     string
+		expected []string
+		err      string
+	}{{
+		line:     "juju deploy mysql",
+		expected: []string{"juju", "deploy", "mysql"},
+	}, {
+		line:     `  juju  config app 'key=a b' "name=\"x\" \y"  `,
+		expected: []string{"juju", "config", "app", "key=a b", `name="x" \y`},
+	}, {
+		line:     `juju a\ b '' x`,
+		expected: []string{"juju", "a b", "", "x"},
+	}, {
+		line: `juju 'unterminated`,
+		err:  `unterminated quote or escape in "juju 'unterminated"`,
+	}} {
+		c.Logf("test %d: %s", i, test.line)
+		words, err := cmdtesting.SplitCommandLine(test.line)
+		if test.err != "" {
+			c.Check(err, gc.ErrorMatches, test.err)
+			continue
+		}
+		c.Check(err, jc.ErrorIsNil)
+		c.Check(words, jc.DeepEquals, test.expected)
+	}
+}
+
+type deployCommand struct {
+	cmd.CommandBase
+	to       string
+	examples []cmd.Example
+}
+
+func (c *deployCommand) Info() *cmd.Info {
+	return &cmd.Info{Name: "deploy", Examples: c.examples}
+}
+
+func (c *deployCommand) SetFlags(f *gnuflag.FlagSet) {
+	f.StringVar(&c.to, "to", "", "")
+}
+
+func (c *deployCommand) Init(args []string) error {
+	if len(args) != 1 {
+		return errors.New("expected one application")
+	}
+	return nil
+}
+
+func (c *deployCommand) Run(ctx *cmd.Context) error {
+	return nil
+}
+
+func newExampleTree(examples ...cmd.Example) cmd.Command {
+	super := cmd.NewSuperCommand(cmd.SuperCommandParams{Name: "juju"})
+	nested := cmd.NewSuperCommand(cmd.SuperCommandParams{Name: "app", UsagePrefix: "juju"})
+	nested.Register(&deployCommand{examples: examples})
+	super.Register(nested)
+	return super
+}
+
+func (*examplesSuite) TestCheckExample(c *gc.C) {
+	for i, test := range []struct {
+		example string
+		err     string
+	}{{
+		example: "juju app deploy mysql --to 2",
+	}, {
+		example: "juju app deploy mysql --target 2",
+		err:     "flag provided but not defined: --target",
+	}, {
+		example: "juju app deploy",
+		err:     "expected one application",
+	}, {
+		example: "juju app remove mysql",
+		err:     "unrecognized command: app remove",
+	}, {
+		example: "jju app deploy mysql",
+		err:     `example does not start with "juju"`,
+	}} {
+		c.Logf("test %d: %s", i, test.example)
+		err := cmdtesting.CheckExample(newExampleTree(), test.example)
+		if test.err != "" {
+			c.Check(err, gc.ErrorMatches, test.err)
+		} else {
+			c.Check(err, jc.ErrorIsNil)
+		}
+	}
+}
+
+func (*examplesSuite) TestCheckExamples(c *gc.C) {
+	examples := []cmd.Example{{
+		Description: "Deploy mysql to machine 2:",
+		Command:     "juju app deploy mysql --to 2",
+	}, {
+		Command: "juju app deploy 'my sql'",
+	}}
+	cmdtesting.CheckExamples(c, func() cmd.Command {
+		return newExampleTree(examples...)
+	})
+}
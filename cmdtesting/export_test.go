@@ -0,0 +1,9 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENSE file for details.
+
+package cmdtesting
+
+var (
+	SplitCommandLine = splitCommandLine
+	CheckExample     = checkExample
+)
@@ -0,0 +1,289 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENSE file for details.
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/juju/gnuflag"
+	goyaml "gopkg.in/yaml.v2"
+)
+
+// ConfigMap is a richer alternative to StringMap for flags that build up
+// structured configuration. Each use of the flag takes a key=value pair,
+// where:
+//   - dotted keys build nested maps, so "a.b.c=1" produces
+//     {"a": {"b": {"c": 1}}}; a literal dot may be escaped as "\.";
+//   - values are parsed as YAML, so numbers, booleans and lists keep
+//     their types, and anything else is a string;
+//   - a value of the form "@path" is replaced by the contents of the file,
+//     read through FileVar, with "@-" meaning stdin; a value that really
+//     starts with "@" is written with "@@", so "key=@@home" sets "@home".
+//
+// Whole YAML documents can also be merged in with the flag returned by
+// FileValue, registered as --set-file by AddFlags. Stdin may only be read
+// once, by one entry. Later values override earlier ones, and setting a
+// key beneath one that holds something other than a map replaces it with
+// a map. Because files are read relative to the command's context, the
+// result is only available from Read, normally called from the command's
+// Run method.
+type ConfigMap struct {
+	entries []configEntry
+}
+
+type configEntry struct {
+	// raw holds the argument as given on the command line.
+	raw string
+
+	// path holds the split key; it is nil for whole documents.
+	path []string
+
+	// value holds the unparsed value, if file is not set.
+	value string
+
+	// file holds the file to read the value or document from.
+	file *FileVar
+}
+
+var _ gnuflag.Value = (*ConfigMap)(nil)
+
+// AddFlags injects the --set and --set-file command line flags into f.
+func (m *ConfigMap) AddFlags(f *gnuflag.FlagSet, usage string) {
+	f.Var(m, "set", usage+" (key=value, key=@file)")
+	f.Var(m.FileValue(), "set-file", usage+" from a YAML file")
+}
+
+// Set implements gnuflag.Value's Set method.
+func (m *ConfigMap) Set(s string) error {
+	// Note that gnuflag will prepend the bad argument to the error message, so
+	// we don't need to restate it here.
+	vals := strings.SplitN(s, "=", 2)
+	if len(vals) != 2 {
+		return errors.New("expected key=value format")
+	}
+	path, err := splitConfigKey(vals[0])
+	if err != nil {
+		return err
+	}
+	entry := configEntry{raw: s, path: path, value: vals[1]}
+	switch {
+	case strings.HasPrefix(vals[1], "@@"):
+		entry.value = vals[1][1:]
+	case strings.HasPrefix(vals[1], "@"):
+		entry.file = &FileVar{Path: vals[1][1:]}
+		entry.file.SetStdin()
+		if entry.file.Path == "" {
+			return errors.New("expected file name after @")
+		}
+	}
+	return m.add(entry)
+}
+
+// add adds entry to the map, checking that stdin is not read twice.
+func (m *ConfigMap) add(entry configEntry) error {
+	if entry.file != nil && entry.file.IsStdin() {
+		if values, documents := m.stdinEntries(); values || documents {
+			return errors.New("stdin can only be read once")
+		}
+	}
+	m.entries = append(m.entries, entry)
+	return nil
+}
+
+// stdinEntries reports whether a key=value entry, and whether a whole
+// document, is read from stdin.
+func (m *ConfigMap) stdinEntries() (values, documents bool) {
+	for _, entry := range m.entries {
+		if entry.file == nil || !entry.file.IsStdin() {
+			continue
+		}
+		if entry.path == nil {
+			documents = true
+		} else {
+			values = true
+		}
+	}
+	return values, documents
+}
+
+// IsStdin reports whether a key=value entry reads from stdin, so that
+// ResolveFlags can check that no other flag reads from it too.
+func (m *ConfigMap) IsStdin() bool {
+	values, _ := m.stdinEntries()
+	return values
+}
+
+// String implements gnuflag.Value's String method.
+func (m *ConfigMap) String() string {
+	raw := make([]string, len(m.entries))
+	for i, entry := range m.entries {
+		raw[i] = entry.raw
+	}
+	return strings.Join(raw, ";")
+}
+
+// FileValue returns a gnuflag.Value that adds the YAML document in the
+// named file to the map. The document must hold a mapping at the top level.
+func (m *ConfigMap) FileValue() gnuflag.Value {
+	return configFileValue{m}
+}
+
+type configFileValue struct {
+	m *ConfigMap
+}
+
+// Set implements gnuflag.Value's Set method.
+func (v configFileValue) Set(s string) error {
+	if s == "" {
+		return ErrNoPath
+	}
+	file := &FileVar{Path: s}
+	file.SetStdin()
+	return v.m.add(configEntry{raw: "@" + s, file: file})
+}
+
+// IsStdin reports whether a document is read from stdin.
+func (v configFileValue) IsStdin() bool {
+	_, documents := v.m.stdinEntries()
+	return documents
+}
+
+// String implements gnuflag.Value's String method.
+func (v configFileValue) String() string {
+	var paths []string
+	for _, entry := range v.m.entries {
+		if entry.path == nil {
+			paths = append(paths, entry.file.Path)
+		}
+	}
+	return strings.Join(paths, ",")
+}
+
+// Read returns the configuration built up from the command line, reading
+// any referenced files relative to ctx. The result only contains maps with
+// string keys, so that it can be serialized as JSON.
+func (m *ConfigMap) Read(ctx *Context) (map[string]interface{}, error) {
+	result := make(map[string]interface{})
+	for _, entry := range m.entries {
+		if entry.path == nil {
+			data, err := entry.file.Read(ctx)
+			if err != nil {
+				return nil, err
+			}
+			var doc interface{}
+			if err := goyaml.Unmarshal(data, &doc); err != nil {
+				return nil, fmt.Errorf("cannot parse %q: %v", entry.file.Path, err)
+			}
+			if doc == nil {
+				continue
+			}
+			docMap, ok := normalizeYAML(doc).(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("%q does not contain a YAML map", entry.file.Path)
+			}
+			mergeConfig(result, docMap)
+			continue
+		}
+		var value interface{}
+		if entry.file != nil {
+			data, err := entry.file.Read(ctx)
+			if err != nil {
+				return nil, err
+			}
+			value = string(data)
+		} else {
+			value = parseConfigValue(entry.value)
+		}
+		setConfigPath(result, entry.path, value)
+	}
+	return result, nil
+}
+
+// splitConfigKey splits a dotted key into its parts, honouring "\."
+// as an escaped dot.
+func splitConfigKey(key string) ([]string, error) {
+	var path []string
+	var part []byte
+	for i := 0; i < len(key); i++ {
+		switch {
+		case key[i] == '\\' && i+1 < len(key) && key[i+1] == '.':
+			part = append(part, '.')
+			i++
+		case key[i] == '.':
+			path = append(path, string(part))
+			part = nil
+		default:
+			part = append(part, key[i])
+		}
+	}
+	path = append(path, string(part))
+	for _, p := range path {
+		if p == "" {
+			return nil, errors.New("key must be non-empty and not contain empty parts")
+		}
+	}
+	return path, nil
+}
+
+// parseConfigValue interprets value as YAML, falling back to the plain
+// string if it cannot be parsed.
+func parseConfigValue(value string) interface{} {
+	if value == "" {
+		return ""
+	}
+	var parsed interface{}
+	if err := goyaml.Unmarshal([]byte(value), &parsed); err != nil || parsed == nil {
+		return value
+	}
+	return normalizeYAML(parsed)
+}
+
+// setConfigPath sets the value at the given path in m, creating any
+// intermediate maps as necessary. As with mergeConfig, an intermediate
+// value that is not a map is replaced by one.
+func setConfigPath(m map[string]interface{}, path []string, value interface{}) {
+	for _, key := range path[:len(path)-1] {
+		child, ok := m[key].(map[string]interface{})
+		if !ok {
+			child = make(map[string]interface{})
+			m[key] = child
+		}
+		m = child
+	}
+	m[path[len(path)-1]] = value
+}
+
+// mergeConfig merges src into dst, recursing into maps present in both.
+func mergeConfig(dst, src map[string]interface{}) {
+	for key, value := range src {
+		srcMap, srcIsMap := value.(map[string]interface{})
+		dstMap, dstIsMap := dst[key].(map[string]interface{})
+		if srcIsMap && dstIsMap {
+			mergeConfig(dstMap, srcMap)
+			continue
+		}
+		dst[key] = value
+	}
+}
+
+// normalizeYAML converts the map[interface{}]interface{} values produced
+// by the YAML decoder into map[string]interface{}.
+func normalizeYAML(value interface{}) interface{} {
+	switch value := value.(type) {
+	case map[interface{}]interface{}:
+		result := make(map[string]interface{}, len(value))
+		for k, v := range value {
+			result[fmt.Sprint(k)] = normalizeYAML(v)
+		}
+		return result
+	case []interface{}:
+		for i, v := range value {
+			value[i] = normalizeYAML(v)
+		}
+		return value
+	}
+	return value
+}
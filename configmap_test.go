@@ -0,0 +1,195 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENSE file for details.
+
+package cmd_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/cmd"
+	"github.com/juju/cmd/cmdtesting"
+)
+
+var _ = gc.Suite(&ConfigMapSuite{})
+
+type ConfigMapSuite struct {
+	testing.IsolationSuite
+}
+
+func (s *ConfigMapSuite) parse(c *gc.C, ctx *cmd.Context, args ...string) (map[string]interface{}, error) {
+	var m cmd.ConfigMap
+	var file cmd.FileVar
+	file.SetStdin()
+	f := cmdtesting.NewFlagSet()
+	m.AddFlags(f, "Set configuration")
+	f.Var(&file, "file", "A file")
+	if err := f.Parse(true, args); err != nil {
+		return nil, err
+	}
+	if err := cmd.ResolveFlags(ctx, f); err != nil {
+		return nil, err
+	}
+	return m.Read(ctx)
+}
+
+func (s *ConfigMapSuite) TestTypedValues(c *gc.C) {
+	ctx := cmdtesting.Context(c)
+	result, err := s.parse(c, ctx,
+		"--set", "name=foo",
+		"--set", "count=3",
+		"--set", "ratio=0.5",
+		"--set", "enabled=true",
+		"--set", "list=[a, 1]",
+		"--set", "empty=",
+		"--set", "quoted='3'",
+	)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, jc.DeepEquals, map[string]interface{}{
+		"name":    "foo",
+		"count":   3,
+		"ratio":   0.5,
+		"enabled": true,
+		"list":    []interface{}{"a", 1},
+		"empty":   "",
+		"quoted":  "3",
+	})
+}
+
+func (s *ConfigMapSuite) TestNestedKeys(c *gc.C) {
+	ctx := cmdtesting.Context(c)
+	result, err := s.parse(c, ctx,
+		"--set", "a.b.c=1",
+		"--set", "a.b.d=x",
+		"--set", "a.e=2",
+		"--set", `f\.g=3`,
+	)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, jc.DeepEquals, map[string]interface{}{
+		"a": map[string]interface{}{
+			"b": map[string]interface{}{
+				"c": 1,
+				"d": "x",
+			},
+			"e": 2,
+		},
+		"f.g": 3,
+	})
+}
+
+func (s *ConfigMapSuite) TestLaterValuesOverride(c *gc.C) {
+	ctx := cmdtesting.Context(c)
+	result, err := s.parse(c, ctx, "--set", "a=1", "--set", "a=2")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, jc.DeepEquals, map[string]interface{}{"a": 2})
+}
+
+func (s *ConfigMapSuite) TestNotAMap(c *gc.C) {
+	ctx := cmdtesting.Context(c)
+	result, err := s.parse(c, ctx, "--set", "a.b=1", "--set", "a.b.c=2", "--set", "d=3", "--set", "d.e=4")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, jc.DeepEquals, map[string]interface{}{
+		"a": map[string]interface{}{
+			"b": map[string]interface{}{"c": 2},
+		},
+		"d": map[string]interface{}{"e": 4},
+	})
+}
+
+func (s *ConfigMapSuite) TestBadKeys(c *gc.C) {
+	ctx := cmdtesting.Context(c)
+	for _, arg := range []string{"a", "=1", "a..b=1", ".a=1"} {
+		_, err := s.parse(c, ctx, "--set", arg)
+		c.Check(err, gc.ErrorMatches, `invalid value ".*" for flag --set: .*`)
+	}
+}
+
+func (s *ConfigMapSuite) TestValueFromFile(c *gc.C) {
+	ctx := cmdtesting.Context(c)
+	err := ioutil.WriteFile(filepath.Join(ctx.Dir, "cert.pem"), []byte("line1\nline2\n"), 0644)
+	c.Assert(err, jc.ErrorIsNil)
+	ctx.Stdin = bytes.NewBufferString("from stdin")
+	result, err := s.parse(c, ctx, "--set", "tls.cert=@cert.pem", "--set", "other=@-")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, jc.DeepEquals, map[string]interface{}{
+		"tls": map[string]interface{}{
+			"cert": "line1\nline2\n",
+		},
+		"other": "from stdin",
+	})
+}
+
+func (s *ConfigMapSuite) TestStdinReadOnce(c *gc.C) {
+	for i, args := range [][]string{
+		{"--set", "a=@-", "--set", "b=@-"},
+		{"--set", "a=@-", "--set-file", "-"},
+	} {
+		c.Logf("test %d: %q", i, args)
+		_, err := s.parse(c, cmdtesting.Context(c), args...)
+		c.Check(err, gc.ErrorMatches, `invalid value "-?.*" for flag --set(-file)?: stdin can only be read once`)
+	}
+}
+
+func (s *ConfigMapSuite) TestStdinClaimedFromOtherFlags(c *gc.C) {
+	_, err := s.parse(c, cmdtesting.Context(c), "--set", "a=@-", "--file", "-")
+	c.Assert(err, gc.ErrorMatches, "--file and --set cannot both read from stdin")
+}
+
+func (s *ConfigMapSuite) TestEscapedAt(c *gc.C) {
+	result, err := s.parse(c, cmdtesting.Context(c), "--set", "user=@@admin")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, jc.DeepEquals, map[string]interface{}{"user": "@admin"})
+}
+
+func (s *ConfigMapSuite) TestValueFromMissingFile(c *gc.C) {
+	ctx := cmdtesting.Context(c)
+	_, err := s.parse(c, ctx, "--set", "cert=@missing.pem")
+	c.Assert(err, gc.ErrorMatches, "open .*missing.pem: no such file or directory")
+}
+
+func (s *ConfigMapSuite) TestSetFile(c *gc.C) {
+	ctx := cmdtesting.Context(c)
+	err := ioutil.WriteFile(filepath.Join(ctx.Dir, "config.yaml"), []byte(`
+a:
+  b: 1
+  c: [x, z]
+d: true
+`), 0644)
+	c.Assert(err, jc.ErrorIsNil)
+	result, err := s.parse(c, ctx,
+		"--set", "a.e=before",
+		"--set-file", "config.yaml",
+		"--set", "a.b=2",
+	)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, jc.DeepEquals, map[string]interface{}{
+		"a": map[string]interface{}{
+			"b": 2,
+			"c": []interface{}{"x", "z"},
+			"e": "before",
+		},
+		"d": true,
+	})
+}
+
+func (s *ConfigMapSuite) TestSetFileNotAMap(c *gc.C) {
+	ctx := cmdtesting.Context(c)
+	err := ioutil.WriteFile(filepath.Join(ctx.Dir, "config.yaml"), []byte("[1, 2]"), 0644)
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = s.parse(c, ctx, "--set-file", "config.yaml")
+	c.Assert(err, gc.ErrorMatches, `"config.yaml" does not contain a YAML map`)
+}
+
+func (s *ConfigMapSuite) TestString(c *gc.C) {
+	var m cmd.ConfigMap
+	c.Assert(m.Set("a=1"), jc.ErrorIsNil)
+	c.Assert(m.FileValue().Set("x.yaml"), jc.ErrorIsNil)
+	c.Assert(m.Set("b=@c"), jc.ErrorIsNil)
+	c.Assert(m.String(), gc.Equals, "a=1;@x.yaml;b=@c")
+	c.Assert(m.FileValue().String(), gc.Equals, "x.yaml")
+}
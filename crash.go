@@ -0,0 +1,168 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENSE file for details.
+
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/juju/gnuflag"
+)
+
+// PanicExitCode is the code returned by Main when a command panics. It is
+// EX_SOFTWARE from sysexits.h.
+const PanicExitCode = 70
+
+// sensitiveFlagWords holds the words that mark a flag's value as secret,
+// so that it is left out of crash reports.
+var sensitiveFlagWords = []string{"password", "secret", "token", "key", "credential"}
+
+// panicError holds a panic recovered from a command, with the stack of the
+// goroutine that panicked.
+type panicError struct {
+	value interface{}
+	stack []byte
+}
+
+func (e *panicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.value)
+}
+
+// reportCrash tells the user that c panicked, writing the details to a
+// crash report file, and returns the code that Main should exit with.
+func reportCrash(c Command, ctx *Context, f *gnuflag.FlagSet, args []string, perr *panicError) int {
+	WriteError(ctx.Stderr, fmt.Errorf("internal error, %v", perr))
+	report := crashReport(c, f, args, perr)
+	file, err := ioutil.TempFile("", strings.Replace(commandName(c), " ", "-", -1)+"-crash-")
+	if err == nil {
+		_, err = file.Write(report)
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	if err != nil {
+		fmt.Fprintf(ctx.Stderr, "cannot write crash report: %v\n%s", err, report)
+		return PanicExitCode
+	}
+	fmt.Fprintf(ctx.Stderr, "A crash report has been written to %s\nPlease include it when reporting this problem.\n", file.Name())
+	return PanicExitCode
+}
+
+// crashReport returns the contents of the crash report for a panic in c.
+func crashReport(c Command, f *gnuflag.FlagSet, args []string, perr *panicError) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "Command: %s\n", strings.Join(selectedPath(c), " "))
+	fmt.Fprintf(&buf, "Args: %q\n", redactArgs(args, sensitiveFlags(selectedFlags(c, f))))
+	if super, ok := c.(*SuperCommand); ok && super.version != "" {
+		fmt.Fprintf(&buf, "Version: %s\n", super.version)
+	}
+	fmt.Fprintf(&buf, "Go: %s %s/%s\n", runtime.Version(), runtime.GOOS, runtime.GOARCH)
+	fmt.Fprintf(&buf, "Time: %s\n", time.Now().UTC().Format(time.RFC3339))
+	fmt.Fprintf(&buf, "\n%v\n\n%s", perr, perr.stack)
+	return buf.Bytes()
+}
+
+// commandName returns the name of the outermost command.
+func commandName(c Command) string {
+	if super, ok := c.(*SuperCommand); ok {
+		return super.Name
+	}
+	return c.Info().Name
+}
+
+// selectedPath returns the names of the commands selected by c's Init.
+func selectedPath(c Command) []string {
+	super, ok := c.(*SuperCommand)
+	if !ok {
+		return []string{c.Info().Name}
+	}
+	if nested, ok := super.action.command.(*SuperCommand); ok {
+		return selectedPath(nested)
+	}
+	path := super.commandPath()
+	if super.action.name != "" {
+		path = append(path, super.action.name)
+	}
+	return path
+}
+
+// selectedFlags returns the flag set used to parse the arguments of the
+// innermost command selected by c, which was set up with f.
+func selectedFlags(c Command, f *gnuflag.FlagSet) *gnuflag.FlagSet {
+	super, ok := c.(*SuperCommand)
+	if !ok || super.commonflags == nil {
+		return f
+	}
+	if nested, ok := super.action.command.(*SuperCommand); ok {
+		return selectedFlags(nested, super.commonflags)
+	}
+	return super.commonflags
+}
+
+// sensitiveFlags returns the names of the flags in f that hold secrets,
+// including any aliases of those flags.
+func sensitiveFlags(f *gnuflag.FlagSet) map[string]bool {
+	names := make(map[string]bool)
+	var values []gnuflag.Value
+	f.VisitAll(func(flag *gnuflag.Flag) {
+		if isSensitiveFlag(flag.Name) {
+			names[flag.Name] = true
+			values = append(values, flagValue(flag))
+		}
+	})
+	f.VisitAll(func(flag *gnuflag.Flag) {
+		if containsValue(values, flagValue(flag)) {
+			names[flag.Name] = true
+		}
+	})
+	return names
+}
+
+// isSensitiveFlag reports whether the flag with the given name probably
+// holds a secret.
+func isSensitiveFlag(name string) bool {
+	name = strings.ToLower(name)
+	for _, word := range sensitiveFlagWords {
+		if strings.Contains(name, word) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactArgs returns a copy of args with the values of sensitive flags
+// replaced.
+func redactArgs(args []string, sensitive map[string]bool) []string {
+	redacted := make([]string, len(args))
+	copy(redacted, args)
+	for i := 0; i < len(redacted); i++ {
+		arg := redacted[i]
+		if arg == "--" {
+			break
+		}
+		if len(arg) < 2 || arg[0] != '-' {
+			continue
+		}
+		name := strings.TrimLeft(arg, "-")
+		value := ""
+		if eq := strings.Index(name, "="); eq >= 0 {
+			name, value = name[:eq], name[eq+1:]
+		}
+		if !sensitive[name] && !isSensitiveFlag(name) {
+			continue
+		}
+		switch {
+		case value != "":
+			redacted[i] = arg[:len(arg)-len(value)] + "REDACTED"
+		case !strings.Contains(arg, "=") && i+1 < len(redacted):
+			i++
+			redacted[i] = "REDACTED"
+		}
+	}
+	return redacted
+}
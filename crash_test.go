@@ -0,0 +1,150 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENSE file for details.
+
+package cmd_test
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+
+	"github.com/juju/gnuflag"
+	"github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/cmd"
+	"github.com/juju/cmd/cmdtesting"
+)
+
+type CrashSuite struct {
+	testing.IsolationSuite
+	dir string
+}
+
+var _ = gc.Suite(&CrashSuite{})
+
+func (s *CrashSuite) SetUpTest(c *gc.C) {
+	s.IsolationSuite.SetUpTest(c)
+	s.dir = c.MkDir()
+	s.PatchEnvironment("TMPDIR", s.dir)
+}
+
+type panicCommand struct {
+	cmd.CommandBase
+	password string
+	region   string
+	token    string
+	inInit   bool
+}
+
+func (c *panicCommand) Info() *cmd.Info {
+	return &cmd.Info{Name: "explode"}
+}
+
+func (c *panicCommand) SetFlags(f *gnuflag.FlagSet) {
+	f.StringVar(&c.password, "p", "", "")
+	f.StringVar(&c.password, "password", "", "")
+	f.StringVar(&c.region, "region", "", "")
+	f.StringVar(&c.token, "api-token", "", "")
+}
+
+func (c *panicCommand) Init(args []string) error {
+	if c.inInit {
+		panic("init went wrong")
+	}
+	return nil
+}
+
+func (c *panicCommand) Run(ctx *cmd.Context) error {
+	panic("run went wrong")
+}
+
+// readReport checks the output of a crashed command and returns the
+// crash report it refers to.
+func (s *CrashSuite) readReport(c *gc.C, ctx *cmd.Context, message string) string {
+	stderr := cmdtesting.Stderr(ctx)
+	m := regexp.MustCompile(`^ERROR internal error, panic: ` + message + `
+A crash report has been written to (.*)
+Please include it when reporting this problem.
+$`).FindStringSubmatch(stderr)
+	c.Assert(m, gc.NotNil, gc.Commentf("stderr: %q", stderr))
+	c.Assert(filepath.Dir(m[1]), gc.Equals, s.dir)
+	data, err := ioutil.ReadFile(m[1])
+	c.Assert(err, jc.ErrorIsNil)
+	return string(data)
+}
+
+func (s *CrashSuite) TestSuperCommandRunPanic(c *gc.C) {
+	super := cmd.NewSuperCommand(cmd.SuperCommandParams{
+		Name:    "juju",
+		Version: "2.4.0",
+	})
+	nested := cmd.NewSuperCommand(cmd.SuperCommandParams{
+		Name:        "cloud",
+		UsagePrefix: "juju",
+	})
+	nested.Register(&panicCommand{})
+	super.Register(nested)
+	ctx := cmdtesting.Context(c)
+	code := cmd.Main(super, ctx, []string{
+		"cloud", "explode", "--password=sekrit", "-p", "sekrit2", "--region", "eu", "--api-token", "t0ken",
+	})
+	c.Assert(code, gc.Equals, cmd.PanicExitCode)
+	report := s.readReport(c, ctx, "run went wrong")
+	c.Check(report, gc.Matches, `(?s)Command: juju cloud explode
+Args: \["cloud" "explode" "--password=REDACTED" "-p" "REDACTED" "--region" "eu" "--api-token" "REDACTED"\]
+Version: 2.4.0
+.*
+panic: run went wrong
+
+.*panicCommand.*`)
+	c.Check(report, gc.Not(jc.Contains), "sekrit")
+	c.Check(report, gc.Not(jc.Contains), "t0ken")
+}
+
+func (s *CrashSuite) TestInitPanic(c *gc.C) {
+	ctx := cmdtesting.Context(c)
+	code := cmd.Main(&panicCommand{inInit: true}, ctx, []string{"--region", "eu"})
+	c.Assert(code, gc.Equals, cmd.PanicExitCode)
+	report := s.readReport(c, ctx, "init went wrong")
+	c.Check(report, gc.Matches, `(?s)Command: explode
+Args: \["--region" "eu"\]
+Go: .*
+panic: init went wrong
+.*`)
+}
+
+func (s *CrashSuite) TestMiddlewareSeesPanic(c *gc.C) {
+	var inv cmd.Invocation
+	super := cmd.NewSuperCommand(cmd.SuperCommandParams{
+		Name: "juju",
+		Middleware: []cmd.Middleware{func(i *cmd.Invocation, next func() error) error {
+			defer func() {
+				inv = *i
+			}()
+			return next()
+		}},
+	})
+	super.Register(&panicCommand{})
+	ctx := cmdtesting.Context(c)
+	code := cmd.Main(super, ctx, []string{"explode"})
+	c.Assert(code, gc.Equals, cmd.PanicExitCode)
+	c.Assert(inv.Phase, gc.Equals, cmd.RunPhase)
+	c.Assert(inv.Code, gc.Equals, cmd.PanicExitCode)
+	c.Assert(inv.Err, gc.ErrorMatches, "panic: run went wrong")
+}
+
+func (s *CrashSuite) TestSuperCommandRunWithoutMain(c *gc.C) {
+	super := cmd.NewSuperCommand(cmd.SuperCommandParams{
+		Name: "juju",
+		Middleware: []cmd.Middleware{func(i *cmd.Invocation, next func() error) error {
+			return next()
+		}},
+	})
+	super.Register(&panicCommand{})
+	err := cmdtesting.InitCommand(super, []string{"explode"})
+	c.Assert(err, jc.ErrorIsNil)
+	ctx := cmdtesting.Context(c)
+	c.Assert(func() { super.Run(ctx) }, gc.PanicMatches, "run went wrong")
+}
@@ -0,0 +1,105 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENSE file for details.
+
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// ErrNoChanges is returned by Context.Edit when the user saves the text
+// without changing it.
+var ErrNoChanges = errors.New("no changes made")
+
+// Edit lets the user edit initial in their preferred editor, as given by
+// $VISUAL or $EDITOR, and returns the result with any lines starting with
+// "#" removed. The text is written to a temporary file whose name ends
+// with suffix, such as ".yaml", so that the editor can recognise its
+// format. If the user saves the file without changing it, Edit returns
+// ErrNoChanges.
+//
+// As with git, the editor setting is interpreted by the shell, so it may
+// include arguments, as in "code --wait".
+//
+// Like the other prompting methods, Edit fails rather than start the
+// editor if --no-prompt was given or stdin is not a terminal.
+func (ctx *Context) Edit(initial []byte, suffix string) ([]byte, error) {
+	if ctx.noPrompt {
+		return nil, errors.New("cannot start editor: prompting disabled by --no-prompt")
+	}
+	if !isTerminal(ctx.Stdin) {
+		return nil, errors.New("cannot start editor: stdin is not a terminal")
+	}
+	dir, err := ioutil.TempDir("", "edit-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "edit"+suffix)
+	if err := ioutil.WriteFile(path, initial, 0600); err != nil {
+		return nil, err
+	}
+
+	editor := ctx.editor()
+	if err := ctx.editorCommand(editor, path).Run(); err != nil {
+		return nil, fmt.Errorf("editor %q failed: %v", editor, err)
+	}
+	edited, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if bytes.Equal(edited, initial) {
+		return nil, ErrNoChanges
+	}
+	return stripComments(edited), nil
+}
+
+// editor returns the user's preferred editor.
+func (ctx *Context) editor() string {
+	for _, name := range []string{"VISUAL", "EDITOR"} {
+		if editor, _ := ctx.lookupEnv(name); editor != "" {
+			return editor
+		}
+	}
+	if runtime.GOOS == "windows" {
+		return "notepad"
+	}
+	return "vi"
+}
+
+// editorCommand returns the command that runs editor on path, attached
+// to the context's standard streams.
+func (ctx *Context) editorCommand(editor, path string) *exec.Cmd {
+	var c *exec.Cmd
+	if runtime.GOOS == "windows" {
+		args := append(strings.Fields(editor), path)
+		c = exec.Command(args[0], args[1:]...)
+	} else {
+		c = exec.Command("sh", "-c", editor+` "$@"`, editor, path)
+	}
+	c.Dir = ctx.Dir
+	c.Stdin = ctx.Stdin
+	c.Stdout = ctx.Stdout
+	c.Stderr = ctx.Stderr
+	return c
+}
+
+// stripComments removes the lines in data that start with "#".
+func stripComments(data []byte) []byte {
+	lines := bytes.SplitAfter(data, []byte("\n"))
+	result := lines[:0]
+	for _, line := range lines {
+		if !bytes.HasPrefix(line, []byte("#")) {
+			result = append(result, line)
+		}
+	}
+	return bytes.Join(result, nil)
+}
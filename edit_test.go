@@ -0,0 +1,113 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENSE file for details.
+
+package cmd_test
+
+import (
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"runtime"
+
+	"github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/cmd"
+	"github.com/juju/cmd/cmdtesting"
+)
+
+type EditSuite struct {
+	testing.IsolationSuite
+}
+
+var _ = gc.Suite(&EditSuite{})
+
+func (s *EditSuite) SetUpTest(c *gc.C) {
+	if runtime.GOOS == "windows" {
+		c.Skip("editor scripts need a POSIX shell")
+	}
+	s.IsolationSuite.SetUpTest(c)
+	s.PatchEnvironment("PATH", "/usr/bin:/bin")
+}
+
+// editContext returns a context whose Stdin is taken to be a terminal, so
+// that Edit will start the editor.
+func editContext(c *gc.C) *cmd.Context {
+	ctx := cmdtesting.Context(c)
+	ctx.Stdin = terminalInput{ctx.Stdin}
+	return ctx
+}
+
+// terminalInput wraps a reader so that it is treated as a terminal.
+type terminalInput struct {
+	io.Reader
+}
+
+func (terminalInput) IsTerminal() bool {
+	return true
+}
+
+// writeEditor writes a shell script that acts as an editor, and returns
+// its path.
+func writeEditor(c *gc.C, script string) string {
+	path := filepath.Join(c.MkDir(), "editor")
+	err := ioutil.WriteFile(path, []byte("#!/bin/sh\n"+script), 0755)
+	c.Assert(err, jc.ErrorIsNil)
+	return path
+}
+
+func (s *EditSuite) TestEdit(c *gc.C) {
+	editor := writeEditor(c, `
+case "$2" in
+*.yaml) ;;
+*) echo "bad file name $2" >&2; exit 1 ;;
+esac
+printf "$1: 2\n# a comment\n" >> "$2"
+echo edited
+`)
+	ctx := editContext(c)
+	ctx.Env = map[string]string{"EDITOR": editor + " b"}
+	result, err := ctx.Edit([]byte("# Edit the config\na: 1\n"), ".yaml")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(string(result), gc.Equals, "a: 1\nb: 2\n")
+	c.Assert(cmdtesting.Stdout(ctx), gc.Equals, "edited\n")
+}
+
+func (s *EditSuite) TestVisualPreferred(c *gc.C) {
+	ctx := editContext(c)
+	ctx.Env = map[string]string{
+		"VISUAL": writeEditor(c, `echo visual > "$1"`),
+		"EDITOR": "false",
+	}
+	result, err := ctx.Edit(nil, ".txt")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(string(result), gc.Equals, "visual\n")
+}
+
+func (s *EditSuite) TestNoChanges(c *gc.C) {
+	ctx := editContext(c)
+	ctx.Env = map[string]string{"EDITOR": "true"}
+	_, err := ctx.Edit([]byte("a: 1\n"), ".yaml")
+	c.Assert(err, gc.Equals, cmd.ErrNoChanges)
+}
+
+func (s *EditSuite) TestEditorFails(c *gc.C) {
+	ctx := editContext(c)
+	ctx.Env = map[string]string{"EDITOR": "false"}
+	_, err := ctx.Edit([]byte("a: 1\n"), ".yaml")
+	c.Assert(err, gc.ErrorMatches, `editor "false" failed: exit status 1`)
+}
+
+func (s *EditSuite) TestNotInteractive(c *gc.C) {
+	ctx := cmdtesting.Context(c)
+	ctx.Env = map[string]string{"EDITOR": writeEditor(c, `echo edited > "$1"`)}
+	_, err := ctx.Edit(nil, ".txt")
+	c.Assert(err, gc.ErrorMatches, `cannot start editor: stdin is not a terminal`)
+
+	ctx = editContext(c)
+	ctx.Env = map[string]string{"EDITOR": writeEditor(c, `echo edited > "$1"`)}
+	(&cmd.Prompting{NoPrompt: true}).Start(ctx)
+	_, err = ctx.Edit(nil, ".txt")
+	c.Assert(err, gc.ErrorMatches, `cannot start editor: prompting disabled by --no-prompt`)
+}
@@ -6,3 +6,17 @@ package cmd
 func NewVersionCommand(version string) Command {
 	return newVersionCommand(version)
 }
+
+// SetProgressTerminal makes the progress items of ctx behave as if
+// ctx.Stderr were a terminal of the given width, without animating
+// spinners. A width of 0 leaves lines untruncated.
+func SetProgressTerminal(ctx *Context, width int) {
+	d := ctx.progressDisplay()
+	d.terminal = true
+	d.columns = width
+}
+
+var (
+	Reflow       = reflow
+	DisplayWidth = displayWidth
+)
@@ -0,0 +1,273 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENSE file for details.
+
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/juju/utils"
+	// The standard library has no xz decoder. This package is a pure
+	// Go implementation with no dependencies of its own.
+	"github.com/ulikunitz/xz"
+)
+
+// FilesVar represents a list of input files, given by repeating the flag.
+// Each path may be a file, a directory, or a shell glob pattern. Patterns
+// are expanded relative to the command's Dir, which allows them to be
+// quoted to avoid expansion by the shell, or to be used where there is no
+// shell at all.
+type FilesVar struct {
+	// Paths holds the paths and patterns as given.
+	Paths []string
+
+	// Recursive, if true, includes the files in subdirectories of
+	// any directory given. Otherwise only the files directly in the
+	// directory are included.
+	Recursive bool
+}
+
+var _ FlagResolver = (*FilesVar)(nil)
+
+// Set appends the path or pattern to f.Paths.
+func (f *FilesVar) Set(v string) error {
+	if v == "" {
+		return ErrNoPath
+	}
+	f.Paths = append(f.Paths, v)
+	return nil
+}
+
+// String returns the paths and patterns given, separated by commas.
+func (f *FilesVar) String() string {
+	return strings.Join(f.Paths, ",")
+}
+
+// ResolveFlag implements FlagResolver by checking that every path and
+// pattern names at least one file.
+func (f *FilesVar) ResolveFlag(ctx *Context) error {
+	_, err := f.Files(ctx)
+	return err
+}
+
+// Files returns the absolute paths of all the files named by f, in the
+// order given, with directories and patterns expanded in lexical order.
+// Each file is only returned once.
+func (f *FilesVar) Files(ctx *Context) ([]string, error) {
+	var files []string
+	seen := make(map[string]bool)
+	add := func(path string) {
+		if !seen[path] {
+			seen[path] = true
+			files = append(files, path)
+		}
+	}
+	for _, p := range f.Paths {
+		path, err := absPath(ctx, p)
+		if err != nil {
+			return nil, err
+		}
+		matches := []string{path}
+		if isGlob(p) {
+			if matches, err = glob(ctx, p); err != nil {
+				return nil, fmt.Errorf("invalid pattern %q: %v", p, err)
+			}
+			if len(matches) == 0 {
+				return nil, fmt.Errorf("no files match %q", p)
+			}
+		}
+		for _, match := range matches {
+			info, err := os.Stat(match)
+			if err != nil {
+				return nil, err
+			}
+			if !info.IsDir() {
+				add(match)
+				continue
+			}
+			if err := f.walkDir(match, add, make(map[string]bool)); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return files, nil
+}
+
+// walkDir calls add for each file in dir, descending into subdirectories
+// if f.Recursive is set. Symbolic links are followed; a link that cannot
+// be followed is an error. The directories already walked are recorded in
+// visited, by their real path, so that links cannot cause a loop.
+func (f *FilesVar) walkDir(dir string, add func(string), visited map[string]bool) error {
+	realDir, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		return err
+	}
+	if visited[realDir] {
+		return nil
+	}
+	visited[realDir] = true
+	names, err := readDirNames(dir)
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		path := filepath.Join(dir, name)
+		info, err := os.Stat(path)
+		if err != nil {
+			return err
+		}
+		switch {
+		case info.IsDir():
+			if f.Recursive {
+				if err := f.walkDir(path, add, visited); err != nil {
+					return err
+				}
+			}
+		case info.Mode().IsRegular():
+			add(path)
+		}
+	}
+	return nil
+}
+
+// readDirNames returns the names of the entries in dir, sorted.
+func readDirNames(dir string) ([]string, error) {
+	d, err := os.Open(dir)
+	if err != nil {
+		return nil, err
+	}
+	defer d.Close()
+	names, err := d.Readdirnames(-1)
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// glob returns the absolute paths of the files matching pattern. Relative
+// patterns are matched relative to ctx.Dir, so that any special
+// characters in the name of ctx.Dir itself are not taken as part of the
+// pattern.
+func glob(ctx *Context, pattern string) ([]string, error) {
+	pattern, err := utils.NormalizePath(pattern)
+	if err != nil {
+		return nil, err
+	}
+	if filepath.IsAbs(pattern) {
+		return filepath.Glob(pattern)
+	}
+	if _, err := filepath.Match(pattern, ""); err != nil {
+		return nil, err
+	}
+	matches := []string{ctx.Dir}
+	for _, part := range strings.Split(filepath.Clean(pattern), string(filepath.Separator)) {
+		var next []string
+		for _, match := range matches {
+			if !isGlob(part) {
+				path := filepath.Join(match, part)
+				if _, err := os.Lstat(path); err == nil {
+					next = append(next, path)
+				}
+				continue
+			}
+			// As with filepath.Glob, directories that cannot be
+			// read are ignored.
+			names, _ := readDirNames(match)
+			for _, name := range names {
+				if ok, _ := filepath.Match(part, name); ok {
+					next = append(next, filepath.Join(match, name))
+				}
+			}
+		}
+		matches = next
+	}
+	return matches, nil
+}
+
+// ForEach calls fn for each of the files returned by Files, with a
+// reader that decompresses the file's contents as described for
+// Decompress. The file is closed when fn returns. ForEach stops at the
+// first error.
+func (f *FilesVar) ForEach(ctx *Context, fn func(path string, r io.Reader) error) error {
+	files, err := f.Files(ctx)
+	if err != nil {
+		return err
+	}
+	for _, path := range files {
+		if err := forFile(path, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func forFile(path string, fn func(path string, r io.Reader) error) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	r, err := Decompress(file)
+	if err != nil {
+		return fmt.Errorf("cannot decompress %s: %v", path, err)
+	}
+	return fn(path, r)
+}
+
+// isGlob reports whether path contains any of the special characters
+// recognised by filepath.Match.
+func isGlob(path string) bool {
+	return strings.ContainsAny(path, "*?[")
+}
+
+var (
+	gzipMagic  = []byte{0x1f, 0x8b}
+	bzip2Magic = []byte("BZh")
+	xzMagic    = []byte{0xfd, '7', 'z', 'X', 'Z', 0x00}
+)
+
+// Decompress returns a reader for the decompressed contents of r if r
+// holds data compressed with gzip, bzip2 or xz, as recognised by the
+// data's magic bytes. Otherwise it returns a reader for r's contents as
+// they are.
+func Decompress(r io.Reader) (io.Reader, error) {
+	br := bufio.NewReader(r)
+	magic, err := br.Peek(len(xzMagic))
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	switch {
+	case bytes.HasPrefix(magic, gzipMagic):
+		zr, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, err
+		}
+		return zr, nil
+	case isBzip2(magic):
+		return bzip2.NewReader(br), nil
+	case bytes.HasPrefix(magic, xzMagic):
+		zr, err := xz.NewReader(br)
+		if err != nil {
+			return nil, err
+		}
+		return zr, nil
+	}
+	return br, nil
+}
+
+// isBzip2 reports whether magic starts with a bzip2 header, which is
+// followed by the block size as a digit from 1 to 9.
+func isBzip2(magic []byte) bool {
+	n := len(bzip2Magic)
+	return bytes.HasPrefix(magic, bzip2Magic) && len(magic) > n && magic[n] >= '1' && magic[n] <= '9'
+}
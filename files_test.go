@@ -0,0 +1,200 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENSE file for details.
+
+package cmd_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/hex"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	"github.com/ulikunitz/xz"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/cmd"
+	"github.com/juju/cmd/cmdtesting"
+)
+
+type FilesVarSuite struct {
+	testing.IsolationSuite
+	ctx *cmd.Context
+}
+
+var _ = gc.Suite(&FilesVarSuite{})
+
+// bzip2Data holds "hello bzip2\n" compressed with bzip2, as the
+// standard library has no bzip2 compressor.
+const bzip2Data = "425a6839314159265359ab6ba1f1000002d9800010400010001264c01020003100d34d04001ea3ef4e51a2078bb9229c284855b5d0f880"
+
+func (s *FilesVarSuite) SetUpTest(c *gc.C) {
+	s.IsolationSuite.SetUpTest(c)
+	s.ctx = cmdtesting.Context(c)
+	for _, dir := range []string{"sub", "sub/deeper"} {
+		err := os.Mkdir(s.ctx.AbsPath(dir), 0755)
+		c.Assert(err, jc.ErrorIsNil)
+	}
+	for _, name := range []string{"a.txt", "b.yaml", "sub/c.txt", "sub/deeper/d.txt"} {
+		err := ioutil.WriteFile(s.ctx.AbsPath(name), []byte(name), 0644)
+		c.Assert(err, jc.ErrorIsNil)
+	}
+}
+
+func (s *FilesVarSuite) files(c *gc.C, f *cmd.FilesVar, args ...string) ([]string, error) {
+	fs := cmdtesting.NewFlagSet()
+	fs.Var(f, "file", "")
+	err := fs.Parse(true, args)
+	c.Assert(err, jc.ErrorIsNil)
+	files, err := f.Files(s.ctx)
+	for i, file := range files {
+		files[i], err = filepath.Rel(s.ctx.Dir, file)
+		c.Assert(err, jc.ErrorIsNil)
+	}
+	return files, err
+}
+
+func (s *FilesVarSuite) TestFiles(c *gc.C) {
+	for i, test := range []struct {
+		args      []string
+		recursive bool
+		expected  []string
+	}{{
+		args:     []string{"--file", "b.yaml", "--file", "a.txt"},
+		expected: []string{"b.yaml", "a.txt"},
+	}, {
+		args:     []string{"--file", "*.txt", "--file", "sub/*.txt"},
+		expected: []string{"a.txt", "sub/c.txt"},
+	}, {
+		args:     []string{"--file", "a.txt", "--file", "*"},
+		expected: []string{"a.txt", "b.yaml", "sub/c.txt"},
+	}, {
+		args:      []string{"--file", "sub"},
+		recursive: true,
+		expected:  []string{"sub/c.txt", "sub/deeper/d.txt"},
+	}, {
+		args:     []string{"--file", filepath.Join(s.ctx.Dir, "sub")},
+		expected: []string{"sub/c.txt"},
+	}} {
+		c.Logf("test %d: %q", i, test.args)
+		f := &cmd.FilesVar{Recursive: test.recursive}
+		files, err := s.files(c, f, test.args...)
+		c.Check(err, jc.ErrorIsNil)
+		c.Check(files, jc.DeepEquals, test.expected)
+	}
+}
+
+func (s *FilesVarSuite) TestSymlinks(c *gc.C) {
+	err := os.Symlink(s.ctx.AbsPath("a.txt"), s.ctx.AbsPath("sub/link.txt"))
+	c.Assert(err, jc.ErrorIsNil)
+	err = os.Symlink(s.ctx.AbsPath("sub"), s.ctx.AbsPath("sub/deeper/loop"))
+	c.Assert(err, jc.ErrorIsNil)
+	files, err := s.files(c, &cmd.FilesVar{Recursive: true}, "--file", "sub")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(files, jc.DeepEquals, []string{"sub/c.txt", "sub/deeper/d.txt", "sub/link.txt"})
+
+	err = os.Symlink(s.ctx.AbsPath("missing.txt"), s.ctx.AbsPath("sub/broken.txt"))
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = s.files(c, &cmd.FilesVar{}, "--file", "sub")
+	c.Assert(err, gc.ErrorMatches, "stat .*broken.txt: no such file or directory")
+}
+
+func (s *FilesVarSuite) TestGlobInDirWithSpecialCharacters(c *gc.C) {
+	dir := filepath.Join(c.MkDir(), "data[1]")
+	err := os.Mkdir(dir, 0755)
+	c.Assert(err, jc.ErrorIsNil)
+	for _, name := range []string{"x.txt", "y.txt"} {
+		err := ioutil.WriteFile(filepath.Join(dir, name), nil, 0644)
+		c.Assert(err, jc.ErrorIsNil)
+	}
+	s.ctx.Dir = dir
+	files, err := s.files(c, &cmd.FilesVar{}, "--file", "*.txt")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(files, jc.DeepEquals, []string{"x.txt", "y.txt"})
+}
+
+func (s *FilesVarSuite) TestNoMatch(c *gc.C) {
+	var f cmd.FilesVar
+	fs := cmdtesting.NewFlagSet()
+	fs.Var(&f, "file", "")
+	err := fs.Parse(true, []string{"--file", "*.json"})
+	c.Assert(err, jc.ErrorIsNil)
+	err = cmd.ResolveFlags(s.ctx, fs)
+	c.Assert(err, gc.ErrorMatches, `invalid value "\*.json" for flag --file: no files match "\*.json"`)
+
+	f = cmd.FilesVar{Paths: []string{"missing.txt"}}
+	_, err = f.Files(s.ctx)
+	c.Assert(err, gc.ErrorMatches, "stat .*missing.txt: no such file or directory")
+}
+
+func (s *FilesVarSuite) TestForEach(c *gc.C) {
+	var gz bytes.Buffer
+	zw := gzip.NewWriter(&gz)
+	io.WriteString(zw, "hello gzip\n")
+	c.Assert(zw.Close(), jc.ErrorIsNil)
+	var xzData bytes.Buffer
+	xw, err := xz.NewWriter(&xzData)
+	c.Assert(err, jc.ErrorIsNil)
+	io.WriteString(xw, "hello xz\n")
+	c.Assert(xw.Close(), jc.ErrorIsNil)
+	bz2, err := hex.DecodeString(bzip2Data)
+	c.Assert(err, jc.ErrorIsNil)
+
+	dir := s.ctx.AbsPath("compressed")
+	c.Assert(os.Mkdir(dir, 0755), jc.ErrorIsNil)
+	for name, data := range map[string][]byte{
+		"1.gz":    gz.Bytes(),
+		"2.bz2":   bz2,
+		"3.xz":    xzData.Bytes(),
+		"4.txt":   []byte("hello plain\n"),
+		"5.empty": nil,
+	} {
+		err := ioutil.WriteFile(filepath.Join(dir, name), data, 0644)
+		c.Assert(err, jc.ErrorIsNil)
+	}
+
+	f := cmd.FilesVar{Paths: []string{"compressed"}}
+	var names, contents []string
+	err = f.ForEach(s.ctx, func(path string, r io.Reader) error {
+		data, err := ioutil.ReadAll(r)
+		names = append(names, filepath.Base(path))
+		contents = append(contents, string(data))
+		return err
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(names, jc.DeepEquals, []string{"1.gz", "2.bz2", "3.xz", "4.txt", "5.empty"})
+	c.Assert(contents, jc.DeepEquals, []string{"hello gzip\n", "hello bzip2\n", "hello xz\n", "hello plain\n", ""})
+}
+
+func (s *FilesVarSuite) TestFileVarDecompress(c *gc.C) {
+	var gz bytes.Buffer
+	zw := gzip.NewWriter(&gz)
+	io.WriteString(zw, "hello gzip\n")
+	c.Assert(zw.Close(), jc.ErrorIsNil)
+	err := ioutil.WriteFile(s.ctx.AbsPath("file.gz"), gz.Bytes(), 0644)
+	c.Assert(err, jc.ErrorIsNil)
+
+	f := cmd.FileVar{Path: "file.gz"}
+	data, err := f.Read(s.ctx)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(data, jc.DeepEquals, gz.Bytes())
+
+	f.Decompress = true
+	data, err = f.Read(s.ctx)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(string(data), gc.Equals, "hello gzip\n")
+
+	f = cmd.FileVar{Path: "-", Decompress: true}
+	f.SetStdin()
+	s.ctx.Stdin = bytes.NewReader(gz.Bytes())
+	rc, err := f.Open(s.ctx)
+	c.Assert(err, jc.ErrorIsNil)
+	defer rc.Close()
+	data, err = ioutil.ReadAll(rc)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(string(data), gc.Equals, "hello gzip\n")
+}
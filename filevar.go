@@ -5,9 +5,11 @@ package cmd
 
 import (
 	"errors"
+	"fmt"
 	"io"
 	"io/ioutil"
 	"os"
+	"strings"
 
 	"github.com/juju/utils"
 )
@@ -20,8 +22,38 @@ type FileVar struct {
 	// StdinMarkers are the Path values that should be interpreted as
 	// stdin. If it is empty then stdin is not supported.
 	StdinMarkers []string
+
+	// Decompress, if true, causes Open and Read to decompress
+	// data compressed with gzip, bzip2 or xz, as described for
+	// the Decompress function.
+	Decompress bool
+
+	// The following constraints are checked by Validate. They only
+	// apply when Path is set and does not refer to stdin.
+
+	// MustExist requires the file to exist.
+	MustExist bool
+
+	// MustNotExist requires the file not to exist, for example
+	// because the command will create it.
+	MustNotExist bool
+
+	// MaxSize, if non-zero, is the largest size in bytes that
+	// an existing file may have.
+	MaxSize int64
+
+	// Perm holds permission bits that an existing file must have,
+	// such as 0400 for a file readable by its owner.
+	Perm os.FileMode
+
+	// Extensions, if not empty, holds the extensions, such as
+	// ".yaml", that the file name may have. They are compared
+	// without regard to case.
+	Extensions []string
 }
 
+var _ FlagResolver = (*FileVar)(nil)
+
 var ErrNoPath = errors.New("path not set")
 
 // Set stores the chosen path name in f.Path.
@@ -51,6 +83,19 @@ func (f FileVar) IsStdin() bool {
 
 // Open opens the file.
 func (f *FileVar) Open(ctx *Context) (io.ReadCloser, error) {
+	rc, err := f.open(ctx)
+	if err != nil || !f.Decompress {
+		return rc, err
+	}
+	r, err := Decompress(rc)
+	if err != nil {
+		rc.Close()
+		return nil, err
+	}
+	return readCloser{r, rc}, nil
+}
+
+func (f *FileVar) open(ctx *Context) (io.ReadCloser, error) {
 	if f.Path == "" {
 		return nil, ErrNoPath
 	}
@@ -58,15 +103,23 @@ func (f *FileVar) Open(ctx *Context) (io.ReadCloser, error) {
 		return ioutil.NopCloser(ctx.Stdin), nil
 	}
 
-	path, err := utils.NormalizePath(f.Path)
+	path, err := absPath(ctx, f.Path)
 	if err != nil {
 		return nil, err
 	}
-	return os.Open(ctx.AbsPath(path))
+	return os.Open(path)
 }
 
 // Read returns the contents of the file.
 func (f *FileVar) Read(ctx *Context) ([]byte, error) {
+	if f.Decompress {
+		rc, err := f.Open(ctx)
+		if err != nil {
+			return nil, err
+		}
+		defer rc.Close()
+		return ioutil.ReadAll(rc)
+	}
 	if f.Path == "" {
 		return nil, ErrNoPath
 	}
@@ -74,14 +127,158 @@ func (f *FileVar) Read(ctx *Context) ([]byte, error) {
 		return ioutil.ReadAll(ctx.Stdin)
 	}
 
-	path, err := utils.NormalizePath(f.Path)
+	path, err := absPath(ctx, f.Path)
 	if err != nil {
 		return nil, err
 	}
-	return ioutil.ReadFile(ctx.AbsPath(path))
+	return ioutil.ReadFile(path)
+}
+
+// readCloser reads from a Reader and closes a separate Closer.
+type readCloser struct {
+	io.Reader
+	io.Closer
 }
 
 // String returns the path to the file.
 func (f *FileVar) String() string {
 	return f.Path
 }
+
+// Validate checks that the file satisfies the constraints set on f.
+func (f *FileVar) Validate(ctx *Context) error {
+	if f.Path == "" || f.IsStdin() {
+		return nil
+	}
+	if len(f.Extensions) > 0 && !hasExtension(f.Path, f.Extensions) {
+		return fmt.Errorf("expected file with extension %s", strings.Join(f.Extensions, ", "))
+	}
+	if !f.MustExist && !f.MustNotExist && f.MaxSize == 0 && f.Perm == 0 {
+		return nil
+	}
+	path, err := absPath(ctx, f.Path)
+	if err != nil {
+		return err
+	}
+	info, err := checkExistence(path, f.MustExist, f.MustNotExist)
+	if err != nil || info == nil {
+		return err
+	}
+	if info.IsDir() {
+		return errors.New("expected a file, not a directory")
+	}
+	if f.MaxSize > 0 && info.Size() > f.MaxSize {
+		return fmt.Errorf("file is larger than %s", FormatByteSize(uint64(f.MaxSize)))
+	}
+	if perm := info.Mode().Perm(); perm&f.Perm != f.Perm {
+		return fmt.Errorf("file permissions %v do not include %v", perm, f.Perm)
+	}
+	return nil
+}
+
+// ResolveFlag implements FlagResolver by calling Validate, so that
+// Main reports a bad path before the command is run.
+func (f *FileVar) ResolveFlag(ctx *Context) error {
+	return f.Validate(ctx)
+}
+
+// DirVar represents a path to a directory. Unlike FileVar, it never
+// refers to stdin.
+type DirVar struct {
+	// Path is the path to the directory.
+	Path string
+
+	// MustExist requires the directory to exist when Path is set.
+	MustExist bool
+
+	// MustNotExist requires nothing to exist at Path when it is
+	// set, for example because the command will create it.
+	MustNotExist bool
+}
+
+var _ FlagResolver = (*DirVar)(nil)
+
+// Set stores the chosen path name in d.Path.
+func (d *DirVar) Set(v string) error {
+	d.Path = v
+	return nil
+}
+
+// String returns the path to the directory.
+func (d *DirVar) String() string {
+	return d.Path
+}
+
+// AbsPath returns the absolute path to the directory, interpreting a
+// relative path relative to ctx.Dir.
+func (d *DirVar) AbsPath(ctx *Context) (string, error) {
+	if d.Path == "" {
+		return "", ErrNoPath
+	}
+	return absPath(ctx, d.Path)
+}
+
+// Validate checks that the directory satisfies the constraints set on d.
+func (d *DirVar) Validate(ctx *Context) error {
+	if d.Path == "" || !d.MustExist && !d.MustNotExist {
+		return nil
+	}
+	path, err := absPath(ctx, d.Path)
+	if err != nil {
+		return err
+	}
+	info, err := checkExistence(path, d.MustExist, d.MustNotExist)
+	if err != nil || info == nil {
+		return err
+	}
+	if !info.IsDir() {
+		return errors.New("expected a directory")
+	}
+	return nil
+}
+
+// ResolveFlag implements FlagResolver by calling Validate.
+func (d *DirVar) ResolveFlag(ctx *Context) error {
+	return d.Validate(ctx)
+}
+
+// absPath returns the absolute form of path, after expanding any
+// leading "~".
+func absPath(ctx *Context, path string) (string, error) {
+	path, err := utils.NormalizePath(path)
+	if err != nil {
+		return "", err
+	}
+	return ctx.AbsPath(path), nil
+}
+
+// checkExistence checks whether path exists, as required by mustExist
+// and mustNotExist. It returns the file's information, or nil if it
+// does not exist.
+func checkExistence(path string, mustExist, mustNotExist bool) (os.FileInfo, error) {
+	info, err := os.Stat(path)
+	switch {
+	case os.IsNotExist(err):
+		if mustExist {
+			return nil, fmt.Errorf("%s does not exist", path)
+		}
+		return nil, nil
+	case err != nil:
+		return nil, err
+	case mustNotExist:
+		return nil, fmt.Errorf("%s already exists", path)
+	}
+	return info, nil
+}
+
+// hasExtension reports whether path ends with one of extensions,
+// ignoring case. Extensions may have more than one part, as in ".tar.gz".
+func hasExtension(path string, extensions []string) bool {
+	path = strings.ToLower(path)
+	for _, ext := range extensions {
+		if strings.HasSuffix(path, strings.ToLower(ext)) {
+			return true
+		}
+	}
+	return false
+}
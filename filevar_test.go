@@ -195,3 +195,115 @@ func fs() (*gnuflag.FlagSet, *cmd.FileVar) {
 	fs.Var(&config, "config", "the config")
 	return fs, &config
 }
+
+func (s *FileVarSuite) TestValidate(c *gc.C) {
+	err := ioutil.WriteFile(s.ctx.AbsPath("big.yaml"), make([]byte, 2048), 0600)
+	c.Assert(err, jc.ErrorIsNil)
+	err = os.Mkdir(s.ctx.AbsPath("dir"), 0755)
+	c.Assert(err, jc.ErrorIsNil)
+	for i, test := range []struct {
+		about string
+		file  cmd.FileVar
+		err   string
+	}{{
+		about: "no path",
+		file:  cmd.FileVar{MustExist: true},
+	}, {
+		about: "stdin",
+		file:  cmd.FileVar{Path: "-", StdinMarkers: []string{"-"}, MustExist: true, Extensions: []string{".yaml"}},
+	}, {
+		about: "must exist",
+		file:  cmd.FileVar{Path: "valid.yaml", MustExist: true},
+	}, {
+		about: "must exist, missing",
+		file:  cmd.FileVar{Path: "missing.yaml", MustExist: true},
+		err:   ".*missing.yaml does not exist",
+	}, {
+		about: "must not exist",
+		file:  cmd.FileVar{Path: "missing.yaml", MustNotExist: true},
+	}, {
+		about: "must not exist, present",
+		file:  cmd.FileVar{Path: "valid.yaml", MustNotExist: true},
+		err:   ".*valid.yaml already exists",
+	}, {
+		about: "directory",
+		file:  cmd.FileVar{Path: "dir", MustExist: true},
+		err:   "expected a file, not a directory",
+	}, {
+		about: "size",
+		file:  cmd.FileVar{Path: "big.yaml", MaxSize: 2048},
+	}, {
+		about: "too large",
+		file:  cmd.FileVar{Path: "big.yaml", MaxSize: 1024},
+		err:   "file is larger than 1KiB",
+	}, {
+		about: "permissions",
+		file:  cmd.FileVar{Path: "big.yaml", Perm: 0600},
+	}, {
+		about: "missing permissions",
+		file:  cmd.FileVar{Path: "big.yaml", Perm: 0644},
+		err:   "file permissions -rw------- do not include -rw-r--r--",
+	}, {
+		about: "extension",
+		file:  cmd.FileVar{Path: "missing.YAML", Extensions: []string{".yml", ".yaml"}},
+	}, {
+		about: "wrong extension",
+		file:  cmd.FileVar{Path: "missing.json", Extensions: []string{".yml", ".yaml"}},
+		err:   "expected file with extension .yml, .yaml",
+	}} {
+		c.Logf("test %d: %s", i, test.about)
+		err := test.file.Validate(s.ctx)
+		if test.err == "" {
+			c.Check(err, jc.ErrorIsNil)
+		} else {
+			c.Check(err, gc.ErrorMatches, test.err)
+		}
+	}
+}
+
+func (s *FileVarSuite) TestValidatedBeforeRun(c *gc.C) {
+	fs, config := fs()
+	config.MustExist = true
+	err := fs.Parse(false, []string{"--config", "missing.yaml"})
+	c.Assert(err, jc.ErrorIsNil)
+	err = cmd.ResolveFlags(s.ctx, fs)
+	c.Assert(err, gc.ErrorMatches, `invalid value "missing.yaml" for flag --config: .*missing.yaml does not exist`)
+}
+
+func (s *FileVarSuite) TestDirVar(c *gc.C) {
+	err := os.Mkdir(s.ctx.AbsPath("dir"), 0755)
+	c.Assert(err, jc.ErrorIsNil)
+
+	var dir cmd.DirVar
+	fs := cmdtesting.NewFlagSet()
+	fs.Var(&dir, "dir", "the directory")
+	err = fs.Parse(false, []string{"--dir", "dir"})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(dir.String(), gc.Equals, "dir")
+	path, err := dir.AbsPath(s.ctx)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(path, gc.Equals, filepath.Join(s.ctx.Dir, "dir"))
+
+	dir.MustExist = true
+	c.Assert(cmd.ResolveFlags(s.ctx, fs), jc.ErrorIsNil)
+	dir.Path = "missing"
+	c.Assert(dir.Validate(s.ctx), gc.ErrorMatches, ".*missing does not exist")
+	dir.Path = "valid.yaml"
+	c.Assert(dir.Validate(s.ctx), gc.ErrorMatches, "expected a directory")
+
+	dir.MustExist, dir.MustNotExist = false, true
+	c.Assert(dir.Validate(s.ctx), gc.ErrorMatches, ".*valid.yaml already exists")
+	dir.Path = "missing"
+	c.Assert(dir.Validate(s.ctx), jc.ErrorIsNil)
+}
+
+func (s *FileVarSuite) TestDirVarTilde(c *gc.C) {
+	dir := cmd.DirVar{Path: "~/foo"}
+	path, err := dir.AbsPath(s.ctx)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(path, gc.Equals, filepath.Join(utils.Home(), "foo"))
+
+	dir.Path = ""
+	_, err = dir.AbsPath(s.ctx)
+	c.Assert(err, gc.Equals, cmd.ErrNoPath)
+}
@@ -0,0 +1,460 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENSE file for details.
+
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/juju/gnuflag"
+)
+
+// NegatableBoolVar defines a bool flag with the given name, together with a
+// --no-<name> flag that sets it to false. If both are given, the last one
+// wins. This gives users a discoverable way to turn off an option that
+// defaults to true, or whose default comes from the environment or a
+// configuration file. The pair is shown as a single --[no-]<name> entry in
+// the help output.
+func NegatableBoolVar(f *gnuflag.FlagSet, p *bool, name string, value bool, usage string) {
+	f.BoolVar(p, name, value, usage)
+	f.Var((*negatedBoolValue)(p), "no-"+name, usage)
+}
+
+// negatedBoolValue implements gnuflag.Value for the --no-<name> twin of a
+// boolean flag, storing the inverse of the value given.
+type negatedBoolValue bool
+
+// Set implements gnuflag.Value.
+func (b *negatedBoolValue) Set(s string) error {
+	v, err := strconv.ParseBool(s)
+	if err != nil {
+		return err
+	}
+	*b = negatedBoolValue(!v)
+	return nil
+}
+
+// String implements gnuflag.Value.
+func (b *negatedBoolValue) String() string {
+	return strconv.FormatBool(!bool(*b))
+}
+
+// IsBoolFlag tells gnuflag that the flag does not take an argument.
+func (b *negatedBoolValue) IsBoolFlag() bool {
+	return true
+}
+
+// isNegatedTwin reports whether flag is the --no-<name> twin of another
+// flag in f.
+func isNegatedTwin(f *gnuflag.FlagSet, flag *gnuflag.Flag) bool {
+	if _, ok := flagValue(flag).(*negatedBoolValue); !ok {
+		return false
+	}
+	return f.Lookup(strings.TrimPrefix(flag.Name, "no-")) != nil
+}
+
+// HideFlags hides the named flags in f, which must already be defined,
+// from help output, along with any other names for the same flags. The
+// flags can still be used as normal; this is intended for internal and
+// debugging flags that users should not need.
+func HideFlags(f *gnuflag.FlagSet, names ...string) {
+	for _, name := range names {
+		flag := f.Lookup(name)
+		if flag == nil {
+			panic(fmt.Sprintf("HideFlags: flag %q not defined", name))
+		}
+		if !isHiddenFlag(flag) {
+			wrapValue(f, flag.Value, &hiddenValue{flag.Value})
+		}
+	}
+}
+
+// wrapValue replaces value with wrapper in every flag in f that has it,
+// so that all the names of a flag are treated alike.
+func wrapValue(f *gnuflag.FlagSet, value, wrapper gnuflag.Value) {
+	f.VisitAll(func(flag *gnuflag.Flag) {
+		if sameValue(flag.Value, value) {
+			flag.Value = wrapper
+		}
+	})
+}
+
+// hiddenValue wraps the value of a flag hidden by HideFlags.
+type hiddenValue struct {
+	gnuflag.Value
+}
+
+// IsBoolFlag tells gnuflag whether the wrapped flag takes an argument.
+func (v *hiddenValue) IsBoolFlag() bool {
+	b, ok := v.Value.(interface {
+		IsBoolFlag() bool
+	})
+	return ok && b.IsBoolFlag()
+}
+
+func (v *hiddenValue) unwrap() gnuflag.Value {
+	return v.Value
+}
+
+// wrappedValue is implemented by flag values that wrap another value
+// to change how the flag is treated.
+type wrappedValue interface {
+	unwrap() gnuflag.Value
+}
+
+// flagValue returns the value of flag as it was defined, without any
+// wrapping added by functions such as HideFlags.
+func flagValue(flag *gnuflag.Flag) gnuflag.Value {
+	value := flag.Value
+	for {
+		wrapped, ok := value.(wrappedValue)
+		if !ok {
+			return value
+		}
+		value = wrapped.unwrap()
+	}
+}
+
+// isHiddenFlag reports whether flag has been hidden by HideFlags.
+func isHiddenFlag(flag *gnuflag.Flag) bool {
+	value := flag.Value
+	for {
+		if _, ok := value.(*hiddenValue); ok {
+			return true
+		}
+		wrapped, ok := value.(wrappedValue)
+		if !ok {
+			return false
+		}
+		value = wrapped.unwrap()
+	}
+}
+
+// FlagDeprecation describes why a flag is deprecated.
+type FlagDeprecation struct {
+	// Replacement holds the name of the flag to use instead, if any.
+	Replacement string
+
+	// RemovedIn holds the version in which the flag will be removed,
+	// if known.
+	RemovedIn string
+
+	// Forward causes values given to the deprecated flag to be set on
+	// the replacement flag too, so that the command need only look at
+	// the replacement.
+	Forward bool
+}
+
+// DeprecateFlag marks the named flag in f, which must already be defined,
+// as deprecated, along with any other names for the same flag. The flag
+// still works, but a warning is logged when it is used, and it is only
+// shown by "help --all". If d.Forward is set, the replacement flag must
+// also be defined.
+func DeprecateFlag(f *gnuflag.FlagSet, name string, d FlagDeprecation) {
+	flag := f.Lookup(name)
+	if flag == nil {
+		panic(fmt.Sprintf("DeprecateFlag: flag %q not defined", name))
+	}
+	value := &deprecatedValue{Value: flag.Value, name: name, deprecation: d}
+	if d.Forward {
+		replacement := f.Lookup(d.Replacement)
+		if replacement == nil {
+			panic(fmt.Sprintf("DeprecateFlag: replacement flag %q not defined", d.Replacement))
+		}
+		value.forward = replacement.Value
+	}
+	wrapValue(f, flag.Value, value)
+}
+
+// deprecatedValue wraps the value of a flag deprecated by DeprecateFlag.
+type deprecatedValue struct {
+	gnuflag.Value
+	name        string
+	deprecation FlagDeprecation
+	forward     gnuflag.Value
+
+	// used records whether the flag was given, and warned whether the
+	// user has been told that it is deprecated.
+	used   bool
+	warned bool
+}
+
+// Set implements gnuflag.Value.
+func (v *deprecatedValue) Set(s string) error {
+	v.used = true
+	if err := v.Value.Set(s); err != nil {
+		return err
+	}
+	if v.forward != nil {
+		return v.forward.Set(s)
+	}
+	return nil
+}
+
+// IsBoolFlag tells gnuflag whether the wrapped flag takes an argument.
+func (v *deprecatedValue) IsBoolFlag() bool {
+	b, ok := v.Value.(interface {
+		IsBoolFlag() bool
+	})
+	return ok && b.IsBoolFlag()
+}
+
+func (v *deprecatedValue) unwrap() gnuflag.Value {
+	return v.Value
+}
+
+// warn logs a warning if the flag was used, unless that has already
+// been done.
+func (v *deprecatedValue) warn(ctx *Context) {
+	if !v.used || v.warned {
+		return
+	}
+	v.warned = true
+	ctx.Warningf("%s", v.message())
+}
+
+// message returns the warning about the flag's deprecation.
+func (v *deprecatedValue) message() string {
+	return fmt.Sprintf("flag %s is %s", flagName(v.name), v.note())
+}
+
+// note describes the flag's deprecation, for example "deprecated, use
+// --new instead; it will be removed in 3.0".
+func (v *deprecatedValue) note() string {
+	note := "deprecated"
+	if v.deprecation.Replacement != "" {
+		note += fmt.Sprintf(", use %s instead", flagName(v.deprecation.Replacement))
+	}
+	if v.deprecation.RemovedIn != "" {
+		note += fmt.Sprintf("; it will be removed in %s", v.deprecation.RemovedIn)
+	}
+	return note
+}
+
+// flagDeprecation returns the value that marks flag as deprecated, or nil
+// if it is not.
+func flagDeprecation(flag *gnuflag.Flag) *deprecatedValue {
+	value := flag.Value
+	for {
+		if d, ok := value.(*deprecatedValue); ok {
+			return d
+		}
+		wrapped, ok := value.(wrappedValue)
+		if !ok {
+			return nil
+		}
+		value = wrapped.unwrap()
+	}
+}
+
+// isShownFlag reports whether flag is listed in help. Deprecated flags
+// are only listed if showDeprecated is set.
+func isShownFlag(flag *gnuflag.Flag, showDeprecated bool) bool {
+	if isHiddenFlag(flag) {
+		return false
+	}
+	return showDeprecated || flagDeprecation(flag) == nil
+}
+
+// printFlagDefaults writes the documentation for the flags in f to w, in
+// the same format as gnuflag's PrintDefaults, but with negatable boolean
+// flags collapsed into a single --[no-]<name> entry and hidden and
+// deprecated flags left out.
+func printFlagDefaults(w io.Writer, f *gnuflag.FlagSet) {
+	writeFlagDefaults(w, f, func(flag *gnuflag.Flag) bool {
+		return isShownFlag(flag, false)
+	}, nil)
+}
+
+// writeFlagDefaults writes the documentation for the flags in f for which
+// show returns true, as printFlagDefaults does. Each flag is listed with
+// its other names, shortest first, and with the default value and usage
+// of the shortest name, as returned by usage if it is not nil. The
+// defaults are those recorded when the flags were defined, so help shown
+// after parsing is not affected by the values given on the command line.
+func writeFlagDefaults(w io.Writer, f *gnuflag.FlagSet, show func(*gnuflag.Flag) bool, usage func(*gnuflag.Flag) string) {
+	negatable := make(map[string]bool)
+	var groups [][]*gnuflag.Flag
+	f.VisitAll(func(flag *gnuflag.Flag) {
+		if !show(flag) {
+			return
+		}
+		if isNegatedTwin(f, flag) {
+			negatable[strings.TrimPrefix(flag.Name, "no-")] = true
+			return
+		}
+		// Flags that share a value are names for the same flag.
+		for i, group := range groups {
+			if sameValue(group[0].Value, flag.Value) {
+				groups[i] = append(group, flag)
+				return
+			}
+		}
+		groups = append(groups, []*gnuflag.Flag{flag})
+	})
+	for _, group := range groups {
+		sort.Sort(flagsByLength(group))
+	}
+	sort.Sort(flagGroupsByName(groups))
+	for _, group := range groups {
+		names := make([]string, len(group))
+		for i, flag := range group {
+			names[i] = flagName(flag.Name)
+			if negatable[flag.Name] {
+				names[i] = flagName("[no-]" + flag.Name)
+			}
+		}
+		format := "%s (= %s)\n    %s\n"
+		if isStringValue(flagValue(group[0])) {
+			format = "%s (= %q)\n    %s\n"
+		}
+		text := group[0].Usage
+		if usage != nil {
+			text = usage(group[0])
+		}
+		fmt.Fprintf(w, format, strings.Join(names, ", "), group[0].DefValue, text)
+	}
+}
+
+// sameValue reports whether a and b are the same flag value, as they are
+// for the names of a flag defined more than once with the same variable.
+func sameValue(a, b gnuflag.Value) bool {
+	return reflect.TypeOf(a) == reflect.TypeOf(b) && reflect.TypeOf(a).Comparable() && a == b
+}
+
+// isStringValue reports whether v is one of gnuflag's string values,
+// whose defaults PrintDefaults quotes.
+func isStringValue(v gnuflag.Value) bool {
+	t := reflect.TypeOf(v)
+	return t.Kind() == reflect.Ptr && t.Elem().Name() == "stringValue" &&
+		t.Elem().PkgPath() == reflect.TypeOf(gnuflag.FlagSet{}).PkgPath()
+}
+
+// flagsByLength sorts the names of a flag as gnuflag does, shortest first.
+type flagsByLength []*gnuflag.Flag
+
+func (f flagsByLength) Len() int      { return len(f) }
+func (f flagsByLength) Swap(i, j int) { f[i], f[j] = f[j], f[i] }
+func (f flagsByLength) Less(i, j int) bool {
+	if len(f[i].Name) != len(f[j].Name) {
+		return len(f[i].Name) < len(f[j].Name)
+	}
+	return f[i].Name < f[j].Name
+}
+
+// flagGroupsByName sorts groups of flag names by their shortest name.
+type flagGroupsByName [][]*gnuflag.Flag
+
+func (f flagGroupsByName) Len() int           { return len(f) }
+func (f flagGroupsByName) Swap(i, j int)      { f[i], f[j] = f[j], f[i] }
+func (f flagGroupsByName) Less(i, j int) bool { return f[i][0].Name < f[j][0].Name }
+
+// deprecatedFlagDefaults returns the documentation for the deprecated
+// flags in f, in the same format as printFlagDefaults.
+func deprecatedFlagDefaults(f *gnuflag.FlagSet) string {
+	var buf bytes.Buffer
+	writeFlagDefaults(&buf, f, func(flag *gnuflag.Flag) bool {
+		return !isHiddenFlag(flag) && flagDeprecation(flag) != nil
+	}, func(flag *gnuflag.Flag) string {
+		return strings.TrimSpace(fmt.Sprintf("%s (%s)", flag.Usage, flagDeprecation(flag).note()))
+	})
+	return buf.String()
+}
+
+// resolveWrappers resolves the values wrapping the value of flag that
+// are FlagResolvers, outermost first.
+func resolveWrappers(ctx *Context, flag *gnuflag.Flag) error {
+	value := flag.Value
+	for {
+		wrapped, ok := value.(wrappedValue)
+		if !ok {
+			return nil
+		}
+		if resolver, ok := value.(FlagResolver); ok {
+			if err := resolver.ResolveFlag(ctx); err != nil {
+				return fmt.Errorf("invalid value for flag %s: %v", flagName(flag.Name), err)
+			}
+		}
+		value = wrapped.unwrap()
+	}
+}
+
+// FlagResolver is implemented by flag values that can only be completed
+// once the command's Context is known, for example because they read a
+// file relative to the context's directory.
+type FlagResolver interface {
+	// ResolveFlag completes the value. It may be called more than
+	// once, and should do nothing if the value is already resolved.
+	ResolveFlag(ctx *Context) error
+}
+
+// stdinValue is implemented by flag values, such as FileVar, that may
+// read from stdin.
+type stdinValue interface {
+	IsStdin() bool
+}
+
+// ResolveFlags resolves every FlagResolver among the flags in f against
+// ctx. It also checks that at most one flag reads from stdin in the
+// invocation that ctx belongs to, and warns about any deprecated flags
+// that were used. Main and SuperCommand call it after Init and before
+// Run.
+func ResolveFlags(ctx *Context, f *gnuflag.FlagSet) error {
+	var err error
+	var resolvers []gnuflag.Value
+	f.VisitAll(func(flag *gnuflag.Flag) {
+		if err != nil {
+			return
+		}
+		if d := flagDeprecation(flag); d != nil {
+			d.warn(ctx)
+		}
+		v := flagValue(flag)
+		if containsValue(resolvers, v) {
+			return
+		}
+		resolvers = append(resolvers, v)
+		if err = resolveWrappers(ctx, flag); err != nil {
+			return
+		}
+		if value, ok := v.(stdinValue); ok && value.IsStdin() {
+			if err = ctx.claimStdin(flag); err != nil {
+				return
+			}
+		}
+		if value, ok := v.(FlagResolver); ok {
+			if resolveErr := value.ResolveFlag(ctx); resolveErr != nil {
+				err = fmt.Errorf("invalid value %q for flag %s: %v", flag.Value, flagName(flag.Name), resolveErr)
+			}
+		}
+	})
+	return err
+}
+
+// containsValue reports whether values holds v. Flag aliases share the
+// same value, so this stops a value being visited under each name.
+func containsValue(values []gnuflag.Value, v gnuflag.Value) bool {
+	if !reflect.TypeOf(v).Comparable() {
+		return false
+	}
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
+
+// flagName returns name as it is written on the command line.
+func flagName(name string) string {
+	if len(name) == 1 {
+		return "-" + name
+	}
+	return "--" + name
+}
@@ -0,0 +1,288 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENSE file for details.
+
+package cmd_test
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/juju/gnuflag"
+	"github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/cmd"
+	"github.com/juju/cmd/cmdtesting"
+)
+
+type FlagsSuite struct {
+	testing.IsolationSuite
+}
+
+var _ = gc.Suite(&FlagsSuite{})
+
+func (s *FlagsSuite) TestNegatableBoolVar(c *gc.C) {
+	for i, test := range []struct {
+		defaultValue bool
+		args         []string
+		expected     bool
+	}{
+		{false, nil, false},
+		{true, nil, true},
+		{true, []string{"--no-color"}, false},
+		{false, []string{"--color"}, true},
+		{false, []string{"--color", "--no-color"}, false},
+		{false, []string{"--no-color", "--color"}, true},
+		{true, []string{"--no-color=false"}, true},
+		{true, []string{"--color=false"}, false},
+	} {
+		c.Logf("test %d: default %v, %q", i, test.defaultValue, test.args)
+		f := cmdtesting.NewFlagSet()
+		var color bool
+		cmd.NegatableBoolVar(f, &color, "color", test.defaultValue, "use color")
+		err := f.Parse(true, test.args)
+		c.Assert(err, jc.ErrorIsNil)
+		c.Check(color, gc.Equals, test.expected)
+	}
+}
+
+func (s *FlagsSuite) TestNegatableBoolVarHelp(c *gc.C) {
+	f := gnuflag.NewFlagSet("test", gnuflag.ContinueOnError)
+	var color, force bool
+	var name string
+	cmd.NegatableBoolVar(f, &color, "color", true, "use color")
+	f.BoolVar(&force, "f", false, "force it")
+	f.BoolVar(&force, "force", false, "")
+	f.StringVar(&name, "name", "", "a name")
+	info := &cmd.Info{Name: "test"}
+	c.Assert(string(info.Help(f)), gc.Equals, `
+Usage: test [flags]
+
+Flags:
+--[no-]color (= true)
+    use color
+-f, --force (= false)
+    force it
+--name (= "")
+    a name
+`[1:])
+}
+
+func (s *FlagsSuite) TestHelpAfterParseShowsDefaults(c *gc.C) {
+	f := gnuflag.NewFlagSet("test", gnuflag.ContinueOnError)
+	var color bool
+	var name string
+	cmd.NegatableBoolVar(f, &color, "color", true, "use color")
+	f.StringVar(&name, "n", "bob", "a name")
+	f.StringVar(&name, "name", "bob", "")
+	err := f.Parse(true, []string{"--no-color", "--name", "alice"})
+	c.Assert(err, jc.ErrorIsNil)
+	info := &cmd.Info{Name: "test"}
+	c.Assert(string(info.Help(f)), gc.Equals, `
+Usage: test [flags]
+
+Flags:
+--[no-]color (= true)
+    use color
+-n, --name (= "bob")
+    a name
+`[1:])
+}
+
+func (s *FlagsSuite) TestBindFlagsNegatable(c *gc.C) {
+	var opts struct {
+		Wait bool `flag:"wait,w" default:"true" negatable:"true" help:"wait for completion"`
+	}
+	f := cmdtesting.NewFlagSet()
+	cmd.BindFlags(f, &opts)
+	c.Assert(opts.Wait, jc.IsTrue)
+	c.Assert(f.Lookup("no-w"), gc.IsNil)
+
+	info := &cmd.Info{Name: "test"}
+	c.Assert(string(info.Help(f)), gc.Equals, `
+Usage: test [flags]
+
+Flags:
+-w, --[no-]wait (= true)
+    wait for completion
+`[1:])
+
+	err := f.Parse(true, []string{"--no-wait"})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(opts.Wait, jc.IsFalse)
+}
+
+func (s *FlagsSuite) TestHideFlags(c *gc.C) {
+	f := gnuflag.NewFlagSet("test", gnuflag.ContinueOnError)
+	var debug, force bool
+	var name string
+	f.BoolVar(&debug, "debug-internals", false, "dump internal state")
+	f.BoolVar(&force, "f", false, "force it")
+	f.BoolVar(&force, "force", false, "")
+	f.StringVar(&name, "name", "", "a name")
+	cmd.HideFlags(f, "debug-internals", "name")
+	info := &cmd.Info{Name: "test"}
+	c.Assert(string(info.Help(f)), gc.Equals, `
+Usage: test [flags]
+
+Flags:
+-f, --force (= false)
+    force it
+`[1:])
+
+	// Hidden flags still work, including boolean flags without a value.
+	err := f.Parse(true, []string{"--debug-internals", "--name", "bob"})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(debug, jc.IsTrue)
+	c.Assert(name, gc.Equals, "bob")
+}
+
+func (s *FlagsSuite) TestHideAllFlags(c *gc.C) {
+	f := gnuflag.NewFlagSet("test", gnuflag.ContinueOnError)
+	var debug bool
+	f.BoolVar(&debug, "debug-internals", false, "dump internal state")
+	cmd.HideFlags(f, "debug-internals")
+	info := &cmd.Info{Name: "test"}
+	c.Assert(string(info.Help(f)), gc.Equals, "Usage: test\n")
+}
+
+func (s *FlagsSuite) TestHideFlagsHidesAliases(c *gc.C) {
+	f := gnuflag.NewFlagSet("test", gnuflag.ContinueOnError)
+	var debug, force bool
+	f.BoolVar(&debug, "d", false, "dump internal state")
+	f.BoolVar(&debug, "debug-internals", false, "dump internal state")
+	f.BoolVar(&force, "force", false, "force it")
+	cmd.HideFlags(f, "debug-internals")
+	info := &cmd.Info{Name: "test"}
+	c.Assert(string(info.Help(f)), gc.Equals, `
+Usage: test [flags]
+
+Flags:
+--force (= false)
+    force it
+`[1:])
+
+	err := f.Parse(true, []string{"-d"})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(debug, jc.IsTrue)
+}
+
+func (s *FlagsSuite) TestHideFlagsUndefined(c *gc.C) {
+	f := gnuflag.NewFlagSet("test", gnuflag.ContinueOnError)
+	c.Assert(func() { cmd.HideFlags(f, "nope") }, gc.PanicMatches, `HideFlags: flag "nope" not defined`)
+}
+
+func (s *FlagsSuite) TestHiddenFlagsResolved(c *gc.C) {
+	f := cmdtesting.NewFlagSet()
+	var token string
+	value := cmd.NewSourcedStringValue("", &token)
+	f.Var(value, "t", "")
+	f.Var(value, "token", "")
+	cmd.HideFlags(f, "t", "token")
+	err := f.Parse(true, []string{"--token", "-"})
+	c.Assert(err, jc.ErrorIsNil)
+	ctx := cmdtesting.Context(c)
+	ctx.Stdin = strings.NewReader("s3cret\n")
+	err = cmd.ResolveFlags(ctx, f)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(token, gc.Equals, "s3cret")
+}
+
+type DeprecatedFlagSuite struct {
+	testing.LoggingCleanupSuite
+}
+
+var _ = gc.Suite(&DeprecatedFlagSuite{})
+
+type renamedFlagCommand struct {
+	cmd.CommandBase
+	model string
+	old   string
+	force bool
+}
+
+func (c *renamedFlagCommand) Info() *cmd.Info {
+	return &cmd.Info{Name: "renamed"}
+}
+
+func (c *renamedFlagCommand) SetFlags(f *gnuflag.FlagSet) {
+	f.StringVar(&c.model, "model", "", "The model to use")
+	f.StringVar(&c.old, "e", "", "The environment to use")
+	f.StringVar(&c.old, "environment", "", "The environment to use")
+	cmd.DeprecateFlag(f, "environment", cmd.FlagDeprecation{
+		Replacement: "model",
+		RemovedIn:   "3.0",
+		Forward:     true,
+	})
+	f.BoolVar(&c.force, "force-it", false, "")
+	cmd.DeprecateFlag(f, "force-it", cmd.FlagDeprecation{})
+}
+
+func (c *renamedFlagCommand) Run(ctx *cmd.Context) error {
+	fmt.Fprintf(ctx.Stdout, "model=%s force=%v\n", c.model, c.force)
+	return nil
+}
+
+func (s *DeprecatedFlagSuite) run(c *gc.C, args ...string) *cmd.Context {
+	super := cmd.NewSuperCommand(cmd.SuperCommandParams{Name: "juju", Log: &cmd.Log{}})
+	super.Register(&renamedFlagCommand{})
+	ctx := cmdtesting.Context(c)
+	code := cmd.Main(super, ctx, args)
+	c.Assert(code, gc.Equals, 0, gc.Commentf("stderr: %s", cmdtesting.Stderr(ctx)))
+	return ctx
+}
+
+func (s *DeprecatedFlagSuite) TestForwarded(c *gc.C) {
+	ctx := s.run(c, "renamed", "--environment", "foo", "--environment", "bar", "--force-it")
+	c.Assert(cmdtesting.Stdout(ctx), gc.Equals, "model=bar force=true\n")
+	c.Assert(cmdtesting.Stderr(ctx), gc.Equals, ""+
+		"WARNING flag --environment is deprecated, use --model instead; it will be removed in 3.0\n"+
+		"WARNING flag --force-it is deprecated\n")
+}
+
+func (s *DeprecatedFlagSuite) TestAlias(c *gc.C) {
+	ctx := s.run(c, "renamed", "-e", "foo")
+	c.Assert(cmdtesting.Stdout(ctx), gc.Equals, "model=foo force=false\n")
+	c.Assert(cmdtesting.Stderr(ctx), gc.Equals,
+		"WARNING flag --environment is deprecated, use --model instead; it will be removed in 3.0\n")
+}
+
+func (s *DeprecatedFlagSuite) TestNotUsed(c *gc.C) {
+	ctx := s.run(c, "renamed", "--model", "foo")
+	c.Assert(cmdtesting.Stdout(ctx), gc.Equals, "model=foo force=false\n")
+	c.Assert(cmdtesting.Stderr(ctx), gc.Equals, "")
+}
+
+func (s *DeprecatedFlagSuite) TestHelp(c *gc.C) {
+	ctx := s.run(c, "help", "renamed")
+	c.Assert(cmdtesting.Stdout(ctx), gc.Not(jc.Contains), "environment")
+	c.Assert(cmdtesting.Stdout(ctx), gc.Not(jc.Contains), "-e")
+	c.Assert(cmdtesting.Stdout(ctx), gc.Not(jc.Contains), "force-it")
+}
+
+func (s *DeprecatedFlagSuite) TestHelpAll(c *gc.C) {
+	ctx := s.run(c, "help", "--all", "renamed")
+	c.Assert(cmdtesting.Stdout(ctx), jc.Contains, `
+Flags:
+--model (= "")
+    The model to use
+
+Deprecated Flags:
+-e, --environment (= "")
+    The environment to use (deprecated, use --model instead; it will be removed in 3.0)
+--force-it (= false)
+    (deprecated)
+`)
+}
+
+func (s *DeprecatedFlagSuite) TestDeprecateUndefined(c *gc.C) {
+	f := cmdtesting.NewFlagSet()
+	c.Assert(func() {
+		cmd.DeprecateFlag(f, "nope", cmd.FlagDeprecation{})
+	}, gc.PanicMatches, `DeprecateFlag: flag "nope" not defined`)
+	f.String("old", "", "")
+	c.Assert(func() {
+		cmd.DeprecateFlag(f, "old", cmd.FlagDeprecation{Replacement: "new", Forward: true})
+	}, gc.PanicMatches, `DeprecateFlag: replacement flag "new" not defined`)
+}
@@ -18,9 +18,17 @@ type helpCommand struct {
 	topic     string
 	topicArgs []string
 	topics    map[string]topic
+	category  string
+	all       bool
+	format    string
 
 	target      *commandReference
 	targetSuper *SuperCommand
+
+	// targetOwner holds the SuperCommand that target is registered
+	// with, which differs from targetSuper if target is itself a
+	// SuperCommand.
+	targetOwner *SuperCommand
 }
 
 func (c *helpCommand) init() {
@@ -31,7 +39,7 @@ func (c *helpCommand) init() {
 	c.topics = map[string]topic{
 		"commands": {
 			short: "Basic help for all commands",
-			long:  func() string { return c.super.describeCommands(true) },
+			long:  func() string { return c.super.describeCommands(true, c.category) },
 		},
 		flagKey: {
 			short: fmt.Sprintf("%vs common to all commands", strings.Title(c.super.FlagKnownAs)),
@@ -72,8 +80,7 @@ command.
 
 	f := gnuflag.NewFlagSetWithFlagKnownAs("", gnuflag.ContinueOnError, c.super.FlagKnownAs)
 	c.super.SetCommonFlags(f)
-	f.SetOutput(buf)
-	f.PrintDefaults()
+	printFlagDefaults(buf, f)
 	return buf.String()
 }
 
@@ -84,15 +91,15 @@ func (c *helpCommand) topicList() string {
 		if topic.alias {
 			continue
 		}
-		if len(name) > longest {
-			longest = len(name)
+		if width := displayWidth(name); width > longest {
+			longest = width
 		}
 		topics = append(topics, name)
 	}
 	sort.Strings(topics)
 	for i, name := range topics {
 		shortHelp := c.topics[name].short
-		topics[i] = fmt.Sprintf("%-*s  %s", longest, name, shortHelp)
+		topics[i] = fmt.Sprintf("%s  %s", padRight(name, longest), shortHelp)
 	}
 	return fmt.Sprintf("%s", strings.Join(topics, "\n"))
 }
@@ -103,16 +110,29 @@ func (c *helpCommand) Info() *Info {
 		Args:        "[topic]",
 		FlagKnownAs: c.super.FlagKnownAs,
 		Purpose:     helpPurpose,
-		Doc: `
-See also: topics
-`,
+		SeeAlso:     []string{"topics"},
 	}
 }
 
+func (c *helpCommand) SetFlags(f *gnuflag.FlagSet) {
+	f.StringVar(&c.category, "category", "", "Only list the commands in this category (with 'help commands')")
+	f.BoolVar(&c.all, "all", false, "Include deprecated "+c.super.FlagKnownAs+"s in command help")
+	format := NewEnumValue("", &c.format, []string{"json", "yaml"})
+	f.Var(format, "format", format.Doc("Describe the command in a structured format"))
+}
+
 func (c *helpCommand) Init(args []string) error {
 	if c.super.notifyHelp != nil {
 		c.super.notifyHelp(args)
 	}
+	if c.category != "" {
+		if len(args) != 1 || args[0] != "commands" {
+			return fmt.Errorf("--category can only be used with %q", c.super.Name+" help commands")
+		}
+		if !c.super.hasCategory(c.category) {
+			return fmt.Errorf("no commands in category %q", c.category)
+		}
+	}
 	logger.Tracef("helpCommand.Init: %#v", args)
 	if len(args) == 0 {
 		// If there is no help topic specified, print basic usage if it is
@@ -129,6 +149,9 @@ func (c *helpCommand) Init(args []string) error {
 		if c.super.missingCallback == nil && len(args) > 1 {
 			return fmt.Errorf("extra arguments to command help: %q", args[1:])
 		}
+		if c.format != "" && args[0] != "commands" {
+			return fmt.Errorf("--format cannot be used with help topic %q", args[0])
+		}
 		logger.Tracef("help not found, setting topic")
 		c.topic, c.topicArgs = args[0], args[1:]
 		return nil
@@ -142,6 +165,7 @@ func (c *helpCommand) Init(args []string) error {
 			return fmt.Errorf("subcommand %q not found", c.topic)
 		}
 		c.target = &commandRef
+		c.targetOwner = c.targetSuper
 		// If there are more args and the target isn't a super command
 		// error out.
 		logger.Tracef("target name: %s", c.target.name)
@@ -154,7 +178,7 @@ func (c *helpCommand) Init(args []string) error {
 	return nil
 }
 
-func (c *helpCommand) getCommandHelp(super *SuperCommand, command Command, alias string) []byte {
+func (c *helpCommand) getCommandHelp(ctx *Context, super *SuperCommand, command Command, alias string) []byte {
 	info := command.Info()
 
 	if command != super {
@@ -192,12 +216,37 @@ func (c *helpCommand) getCommandHelp(super *SuperCommand, command Command, alias
 		// For backward compatibility, the default is 'flag'.
 		flagsAKA = "flag"
 	}
-	f := gnuflag.NewFlagSetWithFlagKnownAs(info.Name, gnuflag.ContinueOnError, flagsAKA)
-	command.SetFlags(f)
+	if command != super {
+		seeAlso := make([]string, len(info.SeeAlso))
+		for i, name := range info.SeeAlso {
+			seeAlso[i] = name
+			if resolved, err := super.seeAlsoRef(name); err == nil {
+				seeAlso[i] = resolved
+			}
+		}
+		info.SeeAlso = seeAlso
+	}
 
-	superf := gnuflag.NewFlagSetWithFlagKnownAs(super.Info().Name, gnuflag.ContinueOnError, flagsAKA)
-	super.SetFlags(superf)
-	return info.HelpWithSuperFlags(superf, f)
+	f := helpFlags(command, info.Name, flagsAKA)
+	superf := helpFlags(super, super.Info().Name, flagsAKA)
+	return info.helpWithSuperFlags(superf, f, helpOptions{
+		showDeprecated: c.all,
+		width:          ctx.helpWidth(),
+	})
+}
+
+// helpFlags returns a flag set holding the flags of command, to be shown
+// in its help. A SuperCommand that has set up its flags already is shown
+// with the flag set it recorded, so that showing its help does not replace
+// the flags it is running with. Other commands are not running when their
+// help is shown, so they define their flags on a new flag set.
+func helpFlags(command Command, name, flagsAKA string) *gnuflag.FlagSet {
+	if super, ok := command.(*SuperCommand); ok && super.flags != nil {
+		return super.flags
+	}
+	f := gnuflag.NewFlagSetWithFlagKnownAs(name, gnuflag.ContinueOnError, flagsAKA)
+	command.SetFlags(f)
+	return f
 }
 
 func (c *helpCommand) Run(ctx *Context) error {
@@ -208,9 +257,13 @@ func (c *helpCommand) Run(ctx *Context) error {
 		return v.Run(ctx)
 	}
 
+	if c.format != "" {
+		return c.writeStructuredHelp(ctx)
+	}
+
 	// If the topic is a registered subcommand, then run the help command with it
 	if c.target != nil {
-		ctx.Stdout.Write(c.getCommandHelp(c.targetSuper, c.target.command, c.target.alias))
+		ctx.Stdout.Write(c.getCommandHelp(ctx, c.targetSuper, c.target.command, c.target.alias))
 		return nil
 	}
 
@@ -220,7 +273,7 @@ func (c *helpCommand) Run(ctx *Context) error {
 		// current action, but we want the info to be printed
 		// as if there was nothing selected.
 		c.super.action.command = nil
-		ctx.Stdout.Write(c.getCommandHelp(c.super, c.super, ""))
+		ctx.Stdout.Write(c.getCommandHelp(ctx, c.super, c.super, ""))
 		return nil
 	}
 
@@ -250,3 +303,18 @@ func (c *helpCommand) Run(ctx *Context) error {
 	}
 	return fmt.Errorf("unknown command or topic for %s", c.topic)
 }
+
+// writeStructuredHelp writes a description of the target command, or of
+// all commands if there is no target, in the format chosen with --format.
+func (c *helpCommand) writeStructuredHelp(ctx *Context) error {
+	// Describing the help command defines its flags again, which
+	// resets them, so take the formatter first.
+	formatter := helpFormatters[c.format]
+	var help CommandHelp
+	if c.target != nil {
+		help = c.targetOwner.describeCommand(c.target, c.super.FlagKnownAs, "")
+	} else {
+		help = c.super.describeCommand(nil, c.super.FlagKnownAs, c.category)
+	}
+	return formatter(ctx.Stdout, help)
+}
@@ -4,8 +4,11 @@
 package cmd_test
 
 import (
+	"encoding/json"
+	"regexp"
 	"strings"
 
+	"github.com/juju/gnuflag"
 	"github.com/juju/loggo"
 	gitjujutesting "github.com/juju/testing"
 	jc "github.com/juju/testing/checkers"
@@ -177,3 +180,450 @@ func (s *HelpCommandSuite) TestNotifyHelp(c *gc.C) {
 
 	c.Assert(called, jc.DeepEquals, [][]string{{"blah"}})
 }
+
+type categoryCommand struct {
+	TestCommand
+	category string
+}
+
+func (c *categoryCommand) Info() *cmd.Info {
+	info := c.TestCommand.Info()
+	info.Category = c.category
+	return info
+}
+
+func (s *HelpCommandSuite) newCategorySuper() *cmd.SuperCommand {
+	super := cmd.NewSuperCommand(cmd.SuperCommandParams{
+		Name:          "jujutest",
+		CategoryOrder: []string{"Models", "Deployment"},
+	})
+	super.Register(&categoryCommand{TestCommand{Name: "deploy"}, "Deployment"})
+	super.Register(&categoryCommand{TestCommand{Name: "add-model"}, "Models"})
+	super.Register(&categoryCommand{TestCommand{Name: "debug-log"}, "Debugging"})
+	super.Register(&categoryCommand{TestCommand{Name: "remove-model"}, "Models"})
+	return super
+}
+
+func (s *HelpCommandSuite) TestHelpCommandsCategories(c *gc.C) {
+	ctx, err := cmdtesting.RunCommand(c, s.newCategorySuper(), "help", "commands")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(cmdtesting.Stdout(ctx), gc.Equals, `
+Models:
+add-model     add-model the juju
+remove-model  remove-model the juju
+
+Deployment:
+deploy        deploy the juju
+
+Debugging:
+debug-log     debug-log the juju
+
+Other:
+help          Show help on a command or other topic.
+`[1:])
+}
+
+func (s *HelpCommandSuite) TestHelpCategories(c *gc.C) {
+	ctx, err := cmdtesting.RunCommand(c, s.newCategorySuper(), "help")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(cmdtesting.Stdout(ctx), jc.Contains, `
+commands:
+
+Models:
+    add-model    - add-model the juju
+    remove-model - remove-model the juju
+
+Deployment:
+    deploy       - deploy the juju
+
+Debugging:
+    debug-log    - debug-log the juju
+
+Other:
+    help         - Show help on a command or other topic.
+`)
+}
+
+func (s *HelpCommandSuite) TestHelpCommandsCategoryFilter(c *gc.C) {
+	ctx, err := cmdtesting.RunCommand(c, s.newCategorySuper(), "help", "commands", "--category", "models")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(cmdtesting.Stdout(ctx), gc.Equals, `
+Models:
+add-model     add-model the juju
+remove-model  remove-model the juju
+`[1:])
+}
+
+func (s *HelpCommandSuite) TestHelpCommandsCategoryErrors(c *gc.C) {
+	_, err := cmdtesting.RunCommand(c, s.newCategorySuper(), "help", "commands", "--category", "Storage")
+	c.Assert(err, gc.ErrorMatches, `no commands in category "Storage"`)
+	_, err = cmdtesting.RunCommand(c, s.newCategorySuper(), "help", "deploy", "--category", "Models")
+	c.Assert(err, gc.ErrorMatches, `--category can only be used with "jujutest help commands"`)
+}
+
+func (s *HelpCommandSuite) TestHiddenCommand(c *gc.C) {
+	super := cmd.NewSuperCommand(cmd.SuperCommandParams{Name: "jujutest"})
+	super.Register(&TestCommand{Name: "blah"})
+	super.Register(&hiddenCommand{TestCommand{Name: "debug-internals-with-long-name"}})
+	ctx, err := cmdtesting.RunCommand(c, super, "help", "commands")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(cmdtesting.Stdout(ctx), gc.Equals, `
+blah  blah the juju
+help  Show help on a command or other topic.
+`[1:])
+
+	// The command can still be run and its help shown.
+	ctx, err = cmdtesting.RunCommand(c, super, "debug-internals-with-long-name", "--option", "ran")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(cmdtesting.Stdout(ctx), gc.Equals, "ran\n")
+	ctx, err = cmdtesting.RunCommand(c, super, "help", "debug-internals-with-long-name")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(cmdtesting.Stdout(ctx), jc.HasPrefix, "Usage: jujutest debug-internals-with-long-name [flags] <something>\n")
+}
+
+type hiddenCommand struct {
+	TestCommand
+}
+
+func (c *hiddenCommand) Info() *cmd.Info {
+	info := c.TestCommand.Info()
+	info.Hidden = true
+	return info
+}
+
+type exampleCommand struct {
+	TestCommand
+}
+
+func (c *exampleCommand) Info() *cmd.Info {
+	info := c.TestCommand.Info()
+	info.Examples = []cmd.Example{{
+		Description: "Run with an option:",
+		Command:     "jujutest blah --option foo",
+	}, {
+		Command: "jujutest blah",
+	}}
+	return info
+}
+
+func (s *HelpCommandSuite) TestExamples(c *gc.C) {
+	super := cmd.NewSuperCommand(cmd.SuperCommandParams{Name: "jujutest"})
+	super.Register(&exampleCommand{TestCommand{Name: "blah", Aliases: []string{"bl"}}})
+	ctx, err := cmdtesting.RunCommand(c, super, "help", "blah")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(cmdtesting.Stdout(ctx), jc.HasSuffix, `
+Details:
+blah-doc
+
+Examples:
+
+Run with an option:
+    jujutest blah --option foo
+
+    jujutest blah
+
+Aliases: bl
+`)
+}
+
+type seeAlsoCommand struct {
+	TestCommand
+	seeAlso []string
+}
+
+func (c *seeAlsoCommand) Info() *cmd.Info {
+	info := c.TestCommand.Info()
+	info.SeeAlso = c.seeAlso
+	return info
+}
+
+func (s *HelpCommandSuite) newSeeAlsoSuper(seeAlso ...string) *cmd.SuperCommand {
+	super := cmd.NewSuperCommand(cmd.SuperCommandParams{Name: "jujutest"})
+	super.Register(&seeAlsoCommand{TestCommand{Name: "blah"}, seeAlso})
+	super.Register(&TestCommand{Name: "other", Aliases: []string{"oth"}})
+	nested := cmd.NewSuperCommand(cmd.SuperCommandParams{Name: "cloud", UsagePrefix: "jujutest"})
+	nested.Register(&seeAlsoCommand{TestCommand{Name: "add"}, []string{"cloud list", "topics"}})
+	nested.Register(&TestCommand{Name: "list"})
+	super.Register(nested)
+	super.AddHelpTopic("basics", "Basic help", "basics")
+	return super
+}
+
+func (s *HelpCommandSuite) TestSeeAlso(c *gc.C) {
+	super := s.newSeeAlsoSuper("oth", "cloud add", "basics")
+	ctx, err := cmdtesting.RunCommand(c, super, "help", "blah")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(cmdtesting.Stdout(ctx), jc.HasSuffix, `
+See also:
+    jujutest other
+    jujutest cloud add
+    jujutest help basics
+`)
+}
+
+func (s *HelpCommandSuite) TestSeeAlsoNested(c *gc.C) {
+	ctx, err := cmdtesting.RunCommand(c, s.newSeeAlsoSuper(), "help", "cloud", "add")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(cmdtesting.Stdout(ctx), jc.HasSuffix, `
+See also:
+    jujutest cloud list
+    jujutest help topics
+`)
+}
+
+func (s *HelpCommandSuite) TestHelpSeeAlsoTopics(c *gc.C) {
+	ctx, err := cmdtesting.RunCommand(c, s.newSeeAlsoSuper(), "help", "help")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(cmdtesting.Stdout(ctx), jc.HasSuffix, `
+See also:
+    jujutest help topics
+`)
+}
+
+func (s *HelpCommandSuite) TestSeeAlsoInvalid(c *gc.C) {
+	for i, test := range []struct {
+		seeAlso []string
+		err     string
+	}{{
+		seeAlso: []string{"unknown"},
+		err:     `invalid see also reference in jujutest blah: unknown command or topic "unknown"`,
+	}, {
+		seeAlso: []string{"cloud remove"},
+		err:     `invalid see also reference in jujutest blah: unknown command or topic "cloud remove"`,
+	}, {
+		seeAlso: []string{"other list"},
+		err:     `invalid see also reference in jujutest blah: "other" is not a SuperCommand`,
+	}, {
+		seeAlso: []string{"list", "add"},
+		err:     `invalid see also reference in jujutest blah: unknown command or topic "add"; jujutest blah: unknown command or topic "list"`,
+	}} {
+		c.Logf("test %d: %q", i, test.seeAlso)
+		super := s.newSeeAlsoSuper(test.seeAlso...)
+		c.Check(super.CheckSeeAlso(), gc.ErrorMatches, regexp.QuoteMeta(test.err))
+		// Invalid references do not stop the command from running,
+		// but are reported when it is initialized.
+		err := cmdtesting.InitCommand(super, []string{"other"})
+		c.Check(err, jc.ErrorIsNil)
+		c.Check(c.GetTestLog(), jc.Contains, "WARNING cmd "+test.err)
+	}
+}
+
+func (s *HelpCommandSuite) TestCheckSeeAlso(c *gc.C) {
+	super := s.newSeeAlsoSuper("oth", "cloud add", "basics")
+	c.Assert(super.CheckSeeAlso(), jc.ErrorIsNil)
+}
+
+func (s *HelpCommandSuite) TestCheckSeeAlsoNested(c *gc.C) {
+	super := cmd.NewSuperCommand(cmd.SuperCommandParams{Name: "jujutest"})
+	nested := cmd.NewSuperCommand(cmd.SuperCommandParams{Name: "cloud", UsagePrefix: "jujutest"})
+	nested.Register(&seeAlsoCommand{TestCommand{Name: "add"}, []string{"list"}})
+	nested.Register(&TestCommand{Name: "list"})
+	super.Register(nested)
+	err := super.CheckSeeAlso()
+	c.Assert(err, gc.ErrorMatches, `invalid see also reference in jujutest cloud add: unknown command or topic "list"`)
+	c.Assert(nested.CheckSeeAlso(), gc.ErrorMatches, err.Error())
+}
+
+type formatCommand struct {
+	TestCommand
+	count  int
+	force  bool
+	mode   string
+	secret string
+	old    string
+	wait   bool
+}
+
+func (c *formatCommand) Info() *cmd.Info {
+	info := c.TestCommand.Info()
+	info.ShowSuperFlags = []string{"description"}
+	return info
+}
+
+func (c *formatCommand) SetFlags(f *gnuflag.FlagSet) {
+	c.TestCommand.SetFlags(f)
+	f.IntVar(&c.count, "n", 1, "")
+	f.IntVar(&c.count, "count", 1, "How many")
+	f.BoolVar(&c.force, "force", false, "Do it anyway")
+	f.Var(cmd.NewEnumValue("fast", &c.mode, []string{"fast", "slow"}), "mode", "How to do it")
+	f.StringVar(&c.secret, "secret", "", "")
+	f.StringVar(&c.old, "old-option", "", "The old way")
+	cmd.NegatableBoolVar(f, &c.wait, "wait", true, "Wait for it")
+	cmd.HideFlags(f, "secret")
+	cmd.DeprecateFlag(f, "old-option", cmd.FlagDeprecation{Replacement: "option", RemovedIn: "3.0"})
+}
+
+func (s *HelpCommandSuite) newFormatSuper() *cmd.SuperCommand {
+	super := cmd.NewSuperCommand(cmd.SuperCommandParams{Name: "jujutest"})
+	super.Register(&formatCommand{TestCommand: TestCommand{Name: "blah", Aliases: []string{"bl"}}})
+	super.RegisterDeprecated(&TestCommand{Name: "old", Minimal: true}, deprecate{replacement: "blah"})
+	super.Register(&hiddenCommand{TestCommand{Name: "secret", Minimal: true}})
+	super.Register(&TestCommand{Name: "cloud", Minimal: true})
+	nested := cmd.NewSuperCommand(cmd.SuperCommandParams{
+		Name:        "model",
+		UsagePrefix: "jujutest",
+		Purpose:     "Manage models",
+	})
+	nested.Register(&TestCommand{Name: "add", Minimal: true})
+	super.Register(nested)
+	return super
+}
+
+func (s *HelpCommandSuite) TestFormatJSON(c *gc.C) {
+	ctx, err := cmdtesting.RunCommand(c, s.newFormatSuper(), "help", "--format=json", "bl")
+	c.Assert(err, jc.ErrorIsNil)
+	var help cmd.CommandHelp
+	err = json.Unmarshal([]byte(cmdtesting.Stdout(ctx)), &help)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(help, jc.DeepEquals, cmd.CommandHelp{
+		Name:    "blah",
+		Path:    []string{"jujutest", "blah"},
+		Purpose: "blah the juju",
+		Doc:     "blah-doc",
+		Args:    "<something>",
+		Aliases: []string{"bl"},
+		Flags: []cmd.FlagHelp{{
+			Name:    "count",
+			Aliases: []string{"n"},
+			Type:    "int",
+			Default: "1",
+			Usage:   "How many",
+		}, {
+			Name:    "force",
+			Type:    "bool",
+			Default: "false",
+			Usage:   "Do it anyway",
+		}, {
+			Name:    "mode",
+			Type:    "enum",
+			Choices: []string{"fast", "slow"},
+			Default: "fast",
+			Usage:   "How to do it",
+		}, {
+			Name:        "old-option",
+			Type:        "string",
+			Default:     "",
+			Usage:       "The old way",
+			Deprecated:  true,
+			Replacement: "option",
+			RemovedIn:   "3.0",
+		}, {
+			Name:    "option",
+			Type:    "string",
+			Default: "",
+			Usage:   "option-doc",
+		}, {
+			Name:      "wait",
+			Type:      "bool",
+			Default:   "true",
+			Usage:     "Wait for it",
+			Negatable: true,
+		}},
+		SuperFlags: []cmd.FlagHelp{{
+			Name:    "description",
+			Type:    "bool",
+			Default: "false",
+			Usage:   "Show short description of plugin, if any",
+		}},
+	})
+}
+
+func (s *HelpCommandSuite) TestFormatLeavesSuperFlags(c *gc.C) {
+	log := &cmd.Log{}
+	super := cmd.NewSuperCommand(cmd.SuperCommandParams{Name: "jujutest", Log: log})
+	ctx := cmdtesting.Context(c)
+	code := cmd.Main(super, ctx, []string{"--debug", "help", "--format=json"})
+	c.Assert(code, gc.Equals, 0)
+	c.Assert(log.Debug, jc.IsTrue)
+	var help cmd.CommandHelp
+	err := json.Unmarshal([]byte(cmdtesting.Stdout(ctx)), &help)
+	c.Assert(err, jc.ErrorIsNil)
+	var defaults []string
+	for _, flag := range help.Flags {
+		if flag.Name == "debug" {
+			defaults = append(defaults, flag.Default)
+		}
+	}
+	c.Assert(defaults, jc.DeepEquals, []string{"false"})
+}
+
+func (s *HelpCommandSuite) TestFormatNestedPath(c *gc.C) {
+	super := cmd.NewSuperCommand(cmd.SuperCommandParams{Name: "jujutest"})
+	nested := cmd.NewSuperCommand(cmd.SuperCommandParams{Name: "cloud"})
+	nested.Register(&TestCommand{Name: "add", Minimal: true})
+	super.Register(nested)
+	ctx, err := cmdtesting.RunCommand(c, super, "help", "--format=json", "cloud")
+	c.Assert(err, jc.ErrorIsNil)
+	var help cmd.CommandHelp
+	err = json.Unmarshal([]byte(cmdtesting.Stdout(ctx)), &help)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(help.Path, jc.DeepEquals, []string{"jujutest", "cloud"})
+	c.Assert(help.Subcommands, gc.HasLen, 2)
+	c.Assert(help.Subcommands[0].Path, jc.DeepEquals, []string{"jujutest", "cloud", "add"})
+
+	ctx, err = cmdtesting.RunCommand(c, super, "help", "--format=json", "cloud", "add")
+	c.Assert(err, jc.ErrorIsNil)
+	err = json.Unmarshal([]byte(cmdtesting.Stdout(ctx)), &help)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(help.Path, jc.DeepEquals, []string{"jujutest", "cloud", "add"})
+}
+
+func (s *HelpCommandSuite) TestFormatCategory(c *gc.C) {
+	ctx, err := cmdtesting.RunCommand(c, s.newCategorySuper(), "help", "commands", "--category", "deployment", "--format=json")
+	c.Assert(err, jc.ErrorIsNil)
+	var help cmd.CommandHelp
+	err = json.Unmarshal([]byte(cmdtesting.Stdout(ctx)), &help)
+	c.Assert(err, jc.ErrorIsNil)
+	var names []string
+	for _, sub := range help.Subcommands {
+		names = append(names, sub.Name)
+	}
+	c.Assert(names, jc.DeepEquals, []string{"deploy"})
+}
+
+func (s *HelpCommandSuite) TestFormatYAML(c *gc.C) {
+	ctx, err := cmdtesting.RunCommand(c, s.newFormatSuper(), "help", "--format", "yaml", "model", "add")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(cmdtesting.Stdout(ctx), gc.Equals, `
+name: add
+path:
+- jujutest
+- model
+- add
+usage-prefix: jujutest
+`[1:])
+}
+
+func (s *HelpCommandSuite) TestFormatCommands(c *gc.C) {
+	ctx, err := cmdtesting.RunCommand(c, s.newFormatSuper(), "help", "--format=json", "commands")
+	c.Assert(err, jc.ErrorIsNil)
+	var help cmd.CommandHelp
+	err = json.Unmarshal([]byte(cmdtesting.Stdout(ctx)), &help)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(help.Path, jc.DeepEquals, []string{"jujutest"})
+	var names []string
+	for _, sub := range help.Subcommands {
+		names = append(names, strings.Join(sub.Path, " "))
+	}
+	c.Assert(names, jc.DeepEquals, []string{
+		"jujutest blah",
+		"jujutest cloud",
+		"jujutest help",
+		"jujutest model",
+		"jujutest old",
+	})
+	old := help.Subcommands[4]
+	c.Assert(old.Deprecated, jc.IsTrue)
+	c.Assert(old.Replacement, gc.Equals, "blah")
+	model := help.Subcommands[3]
+	c.Assert(model.Purpose, gc.Equals, "Manage models")
+	c.Assert(model.Subcommands, gc.HasLen, 2)
+	c.Assert(model.Subcommands[0].Path, jc.DeepEquals, []string{"jujutest", "model", "add"})
+}
+
+func (s *HelpCommandSuite) TestFormatNotForTopics(c *gc.C) {
+	super := s.newFormatSuper()
+	super.AddHelpTopic("basics", "Basic help", "basics")
+	_, err := cmdtesting.RunCommand(c, super, "help", "--format=json", "basics")
+	c.Assert(err, gc.ErrorMatches, `--format cannot be used with help topic "basics"`)
+}
@@ -0,0 +1,286 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENSE file for details.
+
+package cmd
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/juju/gnuflag"
+)
+
+// helpFormatters holds the formatters that can be chosen with the
+// --format flag of the help command.
+var helpFormatters = map[string]Formatter{
+	"json": FormatJson,
+	"yaml": FormatYaml,
+}
+
+// CommandHelp is a structured description of a command, as written by
+// "help --format". It holds the same information as the text help, for
+// the use of tools that would otherwise have to parse that.
+type CommandHelp struct {
+	// Name holds the name of the command.
+	Name string `json:"name" yaml:"name"`
+
+	// Path holds the names of the commands that lead to the command,
+	// starting with the usage prefix, if any, and ending with Name.
+	Path []string `json:"path" yaml:"path"`
+
+	// UsagePrefix holds the usage prefix of the SuperCommand that the
+	// command belongs to, if any.
+	UsagePrefix string `json:"usage-prefix,omitempty" yaml:"usage-prefix,omitempty"`
+
+	Purpose string   `json:"purpose,omitempty" yaml:"purpose,omitempty"`
+	Doc     string   `json:"doc,omitempty" yaml:"doc,omitempty"`
+	Args    string   `json:"args,omitempty" yaml:"args,omitempty"`
+	Aliases []string `json:"aliases,omitempty" yaml:"aliases,omitempty"`
+
+	// Deprecated records whether the command is deprecated, and
+	// Replacement the command to use instead, if there is one.
+	Deprecated  bool   `json:"deprecated,omitempty" yaml:"deprecated,omitempty"`
+	Replacement string `json:"replacement,omitempty" yaml:"replacement,omitempty"`
+
+	// Flags holds the flags of the command, other than hidden ones.
+	Flags []FlagHelp `json:"flags,omitempty" yaml:"flags,omitempty"`
+
+	// SuperFlags holds the flags of the SuperCommand that are listed
+	// in the command's Info.ShowSuperFlags.
+	SuperFlags []FlagHelp `json:"super-flags,omitempty" yaml:"super-flags,omitempty"`
+
+	// Subcommands holds the commands registered with a SuperCommand,
+	// other than hidden ones and aliases.
+	Subcommands []CommandHelp `json:"subcommands,omitempty" yaml:"subcommands,omitempty"`
+}
+
+// FlagHelp is a structured description of a flag, as written by
+// "help --format".
+type FlagHelp struct {
+	// Name holds the longest name of the flag, and Aliases any other
+	// names that set the same value.
+	Name    string   `json:"name" yaml:"name"`
+	Aliases []string `json:"aliases,omitempty" yaml:"aliases,omitempty"`
+
+	// Type holds the type of value that the flag takes, such as
+	// "string", "int", "bool" or "duration". It is "enum" for flags
+	// that take one of Choices, and "value" for other flag types.
+	Type    string   `json:"type" yaml:"type"`
+	Choices []string `json:"choices,omitempty" yaml:"choices,omitempty"`
+
+	Default string `json:"default" yaml:"default"`
+	Usage   string `json:"usage,omitempty" yaml:"usage,omitempty"`
+
+	// Negatable records whether the flag may also be given as
+	// --no-<name>, as defined by NegatableBoolVar.
+	Negatable bool `json:"negatable,omitempty" yaml:"negatable,omitempty"`
+
+	// Deprecated records whether the flag has been deprecated with
+	// DeprecateFlag, and Replacement and RemovedIn hold the details
+	// given there.
+	Deprecated  bool   `json:"deprecated,omitempty" yaml:"deprecated,omitempty"`
+	Replacement string `json:"replacement,omitempty" yaml:"replacement,omitempty"`
+	RemovedIn   string `json:"removed-in,omitempty" yaml:"removed-in,omitempty"`
+}
+
+// describeCommand returns a structured description of the command that
+// ref refers to, or of c itself if ref is nil. The flags are known as
+// flagsAKA unless the command says otherwise. If category is not empty,
+// only the subcommands of c in that category are described.
+func (c *SuperCommand) describeCommand(ref *commandReference, flagsAKA, category string) CommandHelp {
+	if ref == nil {
+		path := c.commandPath()
+		help := CommandHelp{
+			Name:        c.Name,
+			Path:        path,
+			UsagePrefix: c.usagePrefix,
+			Purpose:     c.Purpose,
+			Doc:         strings.TrimSpace(c.Doc),
+			Args:        "<command> ...",
+			Aliases:     c.Aliases,
+			Flags:       describeFlags(c, c.Name, flagsAKA, nil),
+		}
+		help.Subcommands = c.describeSubcommands(flagsAKA, category)
+		return help
+	}
+	if ref.alias != "" {
+		// Describe the command that the alias refers to.
+		if target, ok := c.lookupAlias(ref.alias); ok {
+			ref = &target
+		}
+	}
+	info := ref.command.Info()
+	path := append(c.commandPath(), strings.Fields(ref.name)...)
+	help := CommandHelp{
+		Name:        path[len(path)-1],
+		Path:        path,
+		UsagePrefix: c.usagePrefix,
+		Purpose:     info.Purpose,
+		Doc:         strings.TrimSpace(info.Doc),
+		Args:        info.Args,
+		Aliases:     info.Aliases,
+	}
+	help.Deprecated, help.Replacement = ref.Deprecated()
+	if nested, ok := ref.command.(*SuperCommand); ok {
+		help.Doc = strings.TrimSpace(nested.Doc)
+		help.Flags = describeFlags(nested, strings.Join(path, " "), flagsAKA, nil)
+		help.Subcommands = nested.describeSubcommands(flagsAKA, "")
+		return help
+	}
+	help.Flags = describeFlags(ref.command, strings.Join(path, " "), flagsAKA, nil)
+	if len(info.ShowSuperFlags) > 0 {
+		help.SuperFlags = describeFlags(c, c.Name, flagsAKA, info.ShowSuperFlags)
+	}
+	return help
+}
+
+// lookupAlias returns the reference to the command that an alias refers
+// to, which may be a subcommand of a nested SuperCommand, written as
+// "super sub". The returned reference's name holds the path to the
+// command from c.
+func (c *SuperCommand) lookupAlias(alias string) (commandReference, bool) {
+	names := strings.Fields(alias)
+	super := c
+	for i, name := range names {
+		ref, ok := super.subcmds[name]
+		if !ok || ref.alias != "" {
+			return commandReference{}, false
+		}
+		if i == len(names)-1 {
+			ref.name = alias
+			return ref, true
+		}
+		if super, ok = ref.command.(*SuperCommand); !ok {
+			return commandReference{}, false
+		}
+	}
+	return commandReference{}, false
+}
+
+// describeSubcommands returns structured descriptions of the commands
+// registered with c, in alphabetical order.
+func (c *SuperCommand) describeSubcommands(flagsAKA, category string) []CommandHelp {
+	names := make([]string, 0, len(c.subcmds))
+	for name, action := range c.subcmds {
+		if action.alias != "" {
+			continue
+		}
+		info := action.command.Info()
+		if info.Hidden || category != "" && !strings.EqualFold(info.Category, category) {
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var result []CommandHelp
+	for _, name := range names {
+		ref := c.subcmds[name]
+		result = append(result, c.describeCommand(&ref, flagsAKA, ""))
+	}
+	return result
+}
+
+// describeFlags returns structured descriptions of the flags that command
+// defines, other than hidden ones, in order of name. If only is not nil,
+// only the flags named there are described.
+func describeFlags(command Command, name, flagsAKA string, only []string) []FlagHelp {
+	f := helpFlags(command, name, FlagAlias(command, flagsAKA))
+	wanted := func(name string) bool {
+		if only == nil {
+			return true
+		}
+		for _, w := range only {
+			if strings.EqualFold(name, w) {
+				return true
+			}
+		}
+		return false
+	}
+	negatable := make(map[string]bool)
+	var groups [][]*gnuflag.Flag
+	f.VisitAll(func(flag *gnuflag.Flag) {
+		if isHiddenFlag(flag) || !wanted(flag.Name) {
+			return
+		}
+		if isNegatedTwin(f, flag) {
+			negatable[strings.TrimPrefix(flag.Name, "no-")] = true
+			return
+		}
+		// Flags that share a value are names for the same flag.
+		for i, group := range groups {
+			if sameValue(group[0].Value, flag.Value) {
+				groups[i] = append(group, flag)
+				return
+			}
+		}
+		groups = append(groups, []*gnuflag.Flag{flag})
+	})
+	var result []FlagHelp
+	for _, flags := range groups {
+		longest := flags[0]
+		for _, flag := range flags {
+			if len(flag.Name) > len(longest.Name) {
+				longest = flag
+			}
+		}
+		help := describeFlag(longest)
+		for _, flag := range flags {
+			if flag != longest {
+				help.Aliases = append(help.Aliases, flag.Name)
+			}
+			help.Negatable = help.Negatable || negatable[flag.Name]
+		}
+		result = append(result, help)
+	}
+	sort.Sort(flagHelpByName(result))
+	return result
+}
+
+// flagHelpByName sorts flag descriptions by name.
+type flagHelpByName []FlagHelp
+
+func (f flagHelpByName) Len() int           { return len(f) }
+func (f flagHelpByName) Swap(i, j int)      { f[i], f[j] = f[j], f[i] }
+func (f flagHelpByName) Less(i, j int) bool { return f[i].Name < f[j].Name }
+
+// describeFlag returns a structured description of flag.
+func describeFlag(flag *gnuflag.Flag) FlagHelp {
+	help := FlagHelp{
+		Name:    flag.Name,
+		Default: flag.DefValue,
+		Usage:   flag.Usage,
+	}
+	help.Type, help.Choices = flagType(flagValue(flag))
+	if d := flagDeprecation(flag); d != nil {
+		help.Deprecated = true
+		help.Replacement = d.deprecation.Replacement
+		help.RemovedIn = d.deprecation.RemovedIn
+	}
+	return help
+}
+
+// flagType returns the type of value that a flag with the given value
+// takes, and the choices it allows if it is an enumeration.
+func flagType(value gnuflag.Value) (string, []string) {
+	switch value := value.(type) {
+	case *EnumValue:
+		return "enum", value.Choices
+	case *formatterValue:
+		return "enum", value.Choices
+	}
+	if b, ok := value.(interface {
+		IsBoolFlag() bool
+	}); ok && b.IsBoolFlag() {
+		return "bool", nil
+	}
+	t := reflect.TypeOf(value)
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.PkgPath() == reflect.TypeOf(gnuflag.FlagSet{}).PkgPath() {
+		// The flag types defined by gnuflag, such as stringValue.
+		return strings.TrimSuffix(t.Name(), "Value"), nil
+	}
+	return "value", nil
+}
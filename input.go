@@ -0,0 +1,222 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENSE file for details.
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/juju/gnuflag"
+	goyaml "gopkg.in/yaml.v2"
+)
+
+// Input is responsible for interpreting input-related command line flags
+// and decoding a structured document from a file or from stdin, as the
+// reverse of Output.
+type Input struct {
+	file   FileVar
+	format string
+}
+
+// AddFlags injects the --input (-i) and --input-format command line flags
+// into f. The input may be "-" to read from stdin.
+func (c *Input) AddFlags(f *gnuflag.FlagSet) {
+	c.file.SetStdin()
+	f.Var(&c.file, "i", "Specify an input file, or - for stdin")
+	f.Var(&c.file, "input", "Specify an input file, or - for stdin")
+	format := NewEnumValue("auto", &c.format, []string{"auto", "json", "yaml"})
+	f.Var(format, "input-format", format.Doc("Specify input format"))
+}
+
+// Decode reads the input and decodes it into the value pointed to by
+// target, which should have both json and yaml field tags as required.
+// Fields in the document that do not exist in target are reported as
+// errors, so that typos do not go unnoticed. In "auto" format, the format
+// is chosen by the file's extension (.json, .yaml or .yml); other files and
+// stdin are decoded as JSON if they start with "{" or "[", and as YAML
+// otherwise.
+func (c *Input) Decode(ctx *Context, target interface{}) error {
+	if c.file.Path == "" {
+		return errors.New("no input specified")
+	}
+	data, err := c.file.Read(ctx)
+	if err != nil {
+		return err
+	}
+	name := c.file.Path
+	if c.file.IsStdin() {
+		name = "stdin"
+	}
+	if c.formatFor(data) == "json" {
+		err = decodeJSON(data, target)
+	} else {
+		err = goyaml.UnmarshalStrict(data, target)
+	}
+	if err != nil {
+		return fmt.Errorf("cannot parse %s: %v", name, err)
+	}
+	return nil
+}
+
+// formatFor returns the format in which to decode data: "json" or "yaml".
+func (c *Input) formatFor(data []byte) string {
+	if c.format != "auto" {
+		return c.format
+	}
+	switch strings.ToLower(filepath.Ext(c.file.Path)) {
+	case ".json":
+		return "json"
+	case ".yaml", ".yml":
+		return "yaml"
+	}
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && (trimmed[0] == '{' || trimmed[0] == '[') {
+		return "json"
+	}
+	return "yaml"
+}
+
+// decodeJSON decodes data into target, rejecting unknown fields and
+// trailing data, and adding line numbers to error messages where possible.
+func decodeJSON(data []byte, target interface{}) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	err := dec.Decode(target)
+	switch e := err.(type) {
+	case nil:
+		// Decode stops after the first value, so anything but
+		// white space after it is an error.
+		if _, err := dec.Token(); err != io.EOF {
+			offset := dec.InputOffset()
+			for offset < int64(len(data)) && strings.ContainsRune(" \t\r\n", rune(data[offset])) {
+				offset++
+			}
+			return fmt.Errorf("line %d: unexpected data after JSON value", lineAt(data, offset))
+		}
+		return nil
+	case *json.SyntaxError:
+		return fmt.Errorf("line %d: %v", lineAt(data, e.Offset), err)
+	case *json.UnmarshalTypeError:
+		return fmt.Errorf("line %d: %v", lineAt(data, e.Offset), err)
+	}
+	if strings.HasPrefix(err.Error(), "json: unknown field ") {
+		// The decoder does not report where an unknown field is, so
+		// walk the document to find the first key that has no field
+		// to decode into.
+		dec := json.NewDecoder(bytes.NewReader(data))
+		if key, offset, ok := findUnknownField(dec, reflect.TypeOf(target)); ok {
+			return fmt.Errorf("line %d: unknown field %q", lineAt(data, offset), key)
+		}
+	}
+	return err
+}
+
+var jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+
+// findUnknownField reads the next JSON value from dec, which is to be
+// decoded into a value of type t, and returns the first object key in it
+// that has no matching struct field, with the offset just after the key.
+// A nil t accepts any value.
+func findUnknownField(dec *json.Decoder, t reflect.Type) (string, int64, bool) {
+	tok, err := dec.Token()
+	if err != nil {
+		return "", 0, false
+	}
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t != nil && reflect.PtrTo(t).Implements(jsonUnmarshalerType) {
+		// The type decodes itself, so its fields are not checked.
+		t = nil
+	}
+	switch tok {
+	case json.Delim('{'):
+		for dec.More() {
+			tok, err := dec.Token()
+			if err != nil {
+				return "", 0, false
+			}
+			key, _ := tok.(string)
+			offset := dec.InputOffset()
+			var elem reflect.Type
+			if t != nil {
+				switch t.Kind() {
+				case reflect.Struct:
+					field, ok := jsonField(t, key)
+					if !ok {
+						return key, offset, true
+					}
+					elem = field.Type
+				case reflect.Map:
+					elem = t.Elem()
+				}
+			}
+			if key, offset, ok := findUnknownField(dec, elem); ok {
+				return key, offset, true
+			}
+		}
+		dec.Token()
+	case json.Delim('['):
+		var elem reflect.Type
+		if t != nil && (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) {
+			elem = t.Elem()
+		}
+		for dec.More() {
+			if key, offset, ok := findUnknownField(dec, elem); ok {
+				return key, offset, true
+			}
+		}
+		dec.Token()
+	}
+	return "", 0, false
+}
+
+// jsonField returns the field of the struct type t that the JSON object
+// key decodes into, following the rules of encoding/json.
+func jsonField(t reflect.Type, key string) (reflect.StructField, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name := strings.Split(tag, ",")[0]
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Ptr {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				if found, ok := jsonField(embedded, key); ok {
+					return found, true
+				}
+				continue
+			}
+		}
+		if field.PkgPath != "" && !field.Anonymous {
+			// Unexported fields are not decoded.
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		if strings.EqualFold(name, key) {
+			return field, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+// lineAt returns the line number of the given byte offset in data.
+func lineAt(data []byte, offset int64) int {
+	if offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+	return bytes.Count(data[:offset], []byte("\n")) + 1
+}
@@ -0,0 +1,154 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENSE file for details.
+
+package cmd_test
+
+import (
+	"bytes"
+	"io/ioutil"
+
+	"github.com/juju/gnuflag"
+	"github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/cmd"
+	"github.com/juju/cmd/cmdtesting"
+)
+
+type InputSuite struct {
+	testing.IsolationSuite
+}
+
+var _ = gc.Suite(&InputSuite{})
+
+type inputSpec struct {
+	Name  string   `json:"name" yaml:"name"`
+	Count int      `json:"count" yaml:"count"`
+	Tags  []string `json:"tags" yaml:"tags"`
+
+	Labels map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
+}
+
+type inputCommand struct {
+	cmd.CommandBase
+	in   cmd.Input
+	spec inputSpec
+}
+
+func (c *inputCommand) Info() *cmd.Info {
+	return &cmd.Info{Name: "input"}
+}
+
+func (c *inputCommand) SetFlags(f *gnuflag.FlagSet) {
+	c.in.AddFlags(f)
+}
+
+func (c *inputCommand) Run(ctx *cmd.Context) error {
+	return c.in.Decode(ctx, &c.spec)
+}
+
+func (s *InputSuite) run(c *gc.C, stdin string, files map[string]string, args ...string) (inputSpec, error) {
+	ctx := cmdtesting.Context(c)
+	ctx.Stdin = bytes.NewBufferString(stdin)
+	for name, content := range files {
+		err := ioutil.WriteFile(ctx.AbsPath(name), []byte(content), 0644)
+		c.Assert(err, jc.ErrorIsNil)
+	}
+	var com inputCommand
+	if err := cmdtesting.InitCommand(&com, args); err != nil {
+		return inputSpec{}, err
+	}
+	err := com.Run(ctx)
+	return com.spec, err
+}
+
+func (s *InputSuite) TestDecode(c *gc.C) {
+	files := map[string]string{
+		"spec.yaml": "name: foo\ncount: 2\ntags: [a, b]\n",
+		"spec.json": `{"name": "foo", "count": 2, "tags": ["a", "b"]}`,
+		"spec.txt":  `{"name": "foo", "count": 2, "tags": ["a", "b"]}`,
+		"spec":      "name: foo\ncount: 2\ntags: [a, b]\n",
+	}
+	expected := inputSpec{Name: "foo", Count: 2, Tags: []string{"a", "b"}}
+	for i, args := range [][]string{
+		{"--input", "spec.yaml"},
+		{"-i", "spec.json"},
+		{"-i", "spec.txt"},
+		{"-i", "spec"},
+		{"-i", "spec", "--input-format", "yaml"},
+		{"-i", "spec.txt", "--input-format", "json"},
+	} {
+		c.Logf("test %d: %q", i, args)
+		spec, err := s.run(c, "", files, args...)
+		c.Check(err, jc.ErrorIsNil)
+		c.Check(spec, jc.DeepEquals, expected)
+	}
+}
+
+func (s *InputSuite) TestDecodeStdin(c *gc.C) {
+	spec, err := s.run(c, `{"name": "foo"}`, nil, "-i", "-")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(spec, jc.DeepEquals, inputSpec{Name: "foo"})
+
+	spec, err = s.run(c, "name: bar", nil, "-i", "-")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(spec, jc.DeepEquals, inputSpec{Name: "bar"})
+}
+
+func (s *InputSuite) TestErrors(c *gc.C) {
+	files := map[string]string{
+		"unknown.yaml":  "name: foo\ncolour: red\n",
+		"unknown.json":  "{\n  \"name\": \"foo\",\n  \"colour\": \"red\"\n}",
+		"syntax.json":   "{\n  \"name\": \"foo\",\n  oops\n}",
+		"type.json":     "{\n  \"name\": \"foo\",\n  \"count\": \"many\"\n}",
+		"type.yaml":     "name: foo\ncount: many\n",
+		"nested.json":   "{\n  \"labels\": {\"colour\": \"red\"},\n  \"tags\": [],\n  \"colour\": \"red\"\n}",
+		"trailing.json": "{\"name\": \"foo\"}\n\n{\"name\": \"bar\"}\n",
+		"extra.json":    "{\"name\": \"foo\"}\n}\n",
+	}
+	for i, test := range []struct {
+		args []string
+		err  string
+	}{{
+		err: "no input specified",
+	}, {
+		args: []string{"-i", "unknown.yaml"},
+		err:  `(?s)cannot parse unknown.yaml: .*line 2: field colour not found.*`,
+	}, {
+		args: []string{"-i", "unknown.json"},
+		err:  `cannot parse unknown.json: line 3: unknown field "colour"`,
+	}, {
+		args: []string{"-i", "syntax.json"},
+		err:  `cannot parse syntax.json: line 3: invalid character 'o' .*`,
+	}, {
+		args: []string{"-i", "type.json"},
+		err:  `cannot parse type.json: line 3: json: cannot unmarshal string .*`,
+	}, {
+		args: []string{"-i", "nested.json"},
+		err:  `cannot parse nested.json: line 4: unknown field "colour"`,
+	}, {
+		args: []string{"-i", "trailing.json"},
+		err:  `cannot parse trailing.json: line 3: unexpected data after JSON value`,
+	}, {
+		args: []string{"-i", "extra.json"},
+		err:  `cannot parse extra.json: line 2: unexpected data after JSON value`,
+	}, {
+		args: []string{"-i", "type.yaml"},
+		err:  `(?s)cannot parse type.yaml: .*line 2: cannot unmarshal !!str .many.*`,
+	}, {
+		args: []string{"-i", "unknown.yaml", "--input-format", "xml"},
+		err:  `invalid value "xml" for flag --input-format: expected one of: auto, json, yaml`,
+	}} {
+		c.Logf("test %d: %q", i, test.args)
+		_, err := s.run(c, "", files, test.args...)
+		c.Check(err, gc.ErrorMatches, test.err)
+	}
+}
+
+func (s *InputSuite) TestHelp(c *gc.C) {
+	var com inputCommand
+	f := cmdtesting.NewFlagSet()
+	com.SetFlags(f)
+	c.Assert(f.Lookup("input").Usage, gc.Equals, f.Lookup("i").Usage)
+}
@@ -0,0 +1,95 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENSE file for details.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+)
+
+// PreRunner may be implemented by a Command that needs to prepare before
+// it is run, for example by connecting to an API. PreRun is called after
+// Init and after logging has been started. If it returns an error, Run is
+// not called.
+type PreRunner interface {
+	PreRun(ctx *Context) error
+}
+
+// PostRunner may be implemented by a Command that needs to tidy up after
+// it is run. PostRun is always called, with the error returned by Run, or
+// by PreRun if that failed, and its result is used in place of that
+// error; it should usually return err unchanged. If Run panics, PostRun
+// is called with an error describing the panic, and the panic then
+// continues.
+type PostRunner interface {
+	PostRun(ctx *Context, err error) error
+}
+
+// Closer may be implemented by a Command that holds resources, such as
+// API connections, that must be released however the command finishes.
+// Close is called once, after PostRun, when Run returns or panics. An
+// error from Close is returned only if the command otherwise succeeded.
+//
+// So that Close is still called when the user interrupts the command, an
+// interrupt does not stop a Closer while it runs. The command should
+// watch for interrupts with Context.InterruptNotify and return early; the
+// user is told that the command is being waited for, and a second
+// interrupt has its usual effect.
+type Closer interface {
+	Close() error
+}
+
+// Run runs c in ctx, calling any PreRun, PostRun and Close methods that
+// it implements. It is used by Main and by SuperCommand to run commands.
+func Run(c Command, ctx *Context) (err error) {
+	if closer, ok := c.(Closer); ok {
+		stop := holdInterrupt(ctx)
+		defer func() {
+			stop()
+			if closeErr := closer.Close(); err == nil {
+				err = closeErr
+			}
+		}()
+	}
+	if postRunner, ok := c.(PostRunner); ok {
+		defer func() {
+			if v := recover(); v != nil {
+				// Continue the panic from here, where its
+				// stack is intact, for Main to report.
+				postRunner.PostRun(ctx, &panicError{value: v})
+				panic(v)
+			}
+			err = postRunner.PostRun(ctx, err)
+		}()
+	}
+	if preRunner, ok := c.(PreRunner); ok {
+		if err := preRunner.PreRun(ctx); err != nil {
+			return err
+		}
+	}
+	return c.Run(ctx)
+}
+
+// holdInterrupt stops the first interrupt from terminating the process,
+// leaving it to any handlers registered with ctx.InterruptNotify, so that
+// the running command can return and be closed. The user is told on
+// ctx.Stderr that the command is being waited for. It returns a function
+// that restores the usual handling of interrupts.
+func holdInterrupt(ctx *Context) func() {
+	interrupted := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	ctx.InterruptNotify(interrupted)
+	go func() {
+		select {
+		case <-interrupted:
+			fmt.Fprintln(ctx.Stderr, "interrupted; waiting for the command to finish (interrupt again to stop it now)")
+			ctx.StopInterruptNotify(interrupted)
+		case <-done:
+		}
+	}()
+	return func() {
+		ctx.StopInterruptNotify(interrupted)
+		close(done)
+	}
+}
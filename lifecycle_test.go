@@ -0,0 +1,204 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENSE file for details.
+
+package cmd_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/cmd"
+	"github.com/juju/cmd/cmdtesting"
+)
+
+type LifecycleSuite struct {
+	testing.IsolationSuite
+}
+
+var _ = gc.Suite(&LifecycleSuite{})
+
+// lifecycleCommand records the calls made to it.
+type lifecycleCommand struct {
+	cmd.CommandBase
+	calls    []string
+	preErr   error
+	runErr   error
+	postErr  error
+	closeErr error
+	panics   bool
+	run      func()
+}
+
+func (c *lifecycleCommand) Info() *cmd.Info {
+	return &cmd.Info{Name: "life"}
+}
+
+func (c *lifecycleCommand) Init(args []string) error {
+	c.calls = append(c.calls, "init")
+	return nil
+}
+
+func (c *lifecycleCommand) PreRun(ctx *cmd.Context) error {
+	c.calls = append(c.calls, "prerun")
+	return c.preErr
+}
+
+func (c *lifecycleCommand) Run(ctx *cmd.Context) error {
+	c.calls = append(c.calls, "run")
+	if c.run != nil {
+		c.run()
+	}
+	if c.panics {
+		panic("oops")
+	}
+	return c.runErr
+}
+
+func (c *lifecycleCommand) PostRun(ctx *cmd.Context, err error) error {
+	c.calls = append(c.calls, fmt.Sprintf("postrun %v", err))
+	if c.postErr != nil {
+		return c.postErr
+	}
+	return err
+}
+
+func (c *lifecycleCommand) Close() error {
+	c.calls = append(c.calls, "close")
+	return c.closeErr
+}
+
+func (s *LifecycleSuite) TestSuperCommand(c *gc.C) {
+	for i, test := range []struct {
+		about   string
+		command *lifecycleCommand
+		code    int
+		stderr  string
+		calls   []string
+	}{{
+		about:   "success",
+		command: &lifecycleCommand{},
+		calls:   []string{"init", "prerun", "run", "postrun <nil>", "close"},
+	}, {
+		about:   "prerun aborts",
+		command: &lifecycleCommand{preErr: errors.New("no connection")},
+		code:    1,
+		stderr:  "ERROR no connection\n",
+		calls:   []string{"init", "prerun", "postrun no connection", "close"},
+	}, {
+		about:   "run fails",
+		command: &lifecycleCommand{runErr: errors.New("bad")},
+		code:    1,
+		stderr:  "ERROR bad\n",
+		calls:   []string{"init", "prerun", "run", "postrun bad", "close"},
+	}, {
+		about:   "postrun replaces error",
+		command: &lifecycleCommand{runErr: errors.New("bad"), postErr: errors.New("worse")},
+		code:    1,
+		stderr:  "ERROR worse\n",
+		calls:   []string{"init", "prerun", "run", "postrun bad", "close"},
+	}, {
+		about:   "close fails",
+		command: &lifecycleCommand{closeErr: errors.New("cannot close")},
+		code:    1,
+		stderr:  "ERROR cannot close\n",
+		calls:   []string{"init", "prerun", "run", "postrun <nil>", "close"},
+	}, {
+		about:   "close error hidden by run error",
+		command: &lifecycleCommand{runErr: errors.New("bad"), closeErr: errors.New("cannot close")},
+		code:    1,
+		stderr:  "ERROR bad\n",
+		calls:   []string{"init", "prerun", "run", "postrun bad", "close"},
+	}} {
+		c.Logf("test %d: %s", i, test.about)
+		super := cmd.NewSuperCommand(cmd.SuperCommandParams{Name: "super"})
+		super.Register(test.command)
+		ctx := cmdtesting.Context(c)
+		code := cmd.Main(super, ctx, []string{"life"})
+		c.Check(code, gc.Equals, test.code)
+		c.Check(cmdtesting.Stderr(ctx), gc.Equals, test.stderr)
+		c.Check(test.command.calls, jc.DeepEquals, test.calls)
+	}
+}
+
+func (s *LifecycleSuite) TestMain(c *gc.C) {
+	command := &lifecycleCommand{}
+	code := cmd.Main(command, cmdtesting.Context(c), nil)
+	c.Assert(code, gc.Equals, 0)
+	c.Assert(command.calls, jc.DeepEquals, []string{"init", "prerun", "run", "postrun <nil>", "close"})
+}
+
+func (s *LifecycleSuite) TestRunCommand(c *gc.C) {
+	command := &lifecycleCommand{}
+	_, err := cmdtesting.RunCommand(c, command)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(command.calls, jc.DeepEquals, []string{"init", "prerun", "run", "postrun <nil>", "close"})
+}
+
+func (s *LifecycleSuite) TestCloseAfterPanic(c *gc.C) {
+	s.PatchEnvironment("TMPDIR", c.MkDir())
+	command := &lifecycleCommand{panics: true}
+	super := cmd.NewSuperCommand(cmd.SuperCommandParams{Name: "super"})
+	super.Register(command)
+	code := cmd.Main(super, cmdtesting.Context(c), []string{"life"})
+	c.Assert(code, gc.Equals, cmd.PanicExitCode)
+	c.Assert(command.calls, jc.DeepEquals, []string{"init", "prerun", "run", "postrun panic: oops", "close"})
+}
+
+func (s *LifecycleSuite) TestCloseOnInterrupt(c *gc.C) {
+	if runtime.GOOS == "windows" {
+		c.Skip("interrupts cannot be sent to the current process on windows")
+	}
+	ctx := cmdtesting.Context(c)
+	stderr := &lockedBuffer{}
+	ctx.Stderr = stderr
+	const notice = "interrupted; waiting for the command to finish (interrupt again to stop it now)\n"
+	command := &lifecycleCommand{}
+	command.run = func() {
+		// The command does not watch for interrupts itself, so
+		// without the lifecycle's handler the interrupt would
+		// terminate the test.
+		p, err := os.FindProcess(os.Getpid())
+		c.Assert(err, jc.ErrorIsNil)
+		err = p.Signal(os.Interrupt)
+		c.Assert(err, jc.ErrorIsNil)
+		timeout := time.After(testing.LongWait)
+		for stderr.String() != notice {
+			select {
+			case <-time.After(testing.ShortWait):
+			case <-timeout:
+				c.Fatalf("interrupt not noticed")
+			}
+		}
+	}
+	err := cmd.Run(command, ctx)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(command.calls, jc.DeepEquals, []string{"prerun", "run", "postrun <nil>", "close"})
+}
+
+// lockedBuffer is a bytes.Buffer that may be written and read
+// concurrently.
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
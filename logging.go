@@ -27,6 +27,13 @@ type Log struct {
 	ShowLog       bool
 	Config        string
 
+	// Verbosity holds the number of times -v was given. Each level
+	// shows more of the log on stderr: 1 shows INFO, 2 shows DEBUG and 3
+	// or more shows TRACE. Giving -v also sets Verbose. Setting Verbose
+	// on its own, as --verbose does, only shows the output written with
+	// Context.Verbosef, as it always has.
+	Verbosity int
+
 	// NewWriter creates a new logging writer for a specified target.
 	NewWriter func(target io.Writer) loggo.Writer
 }
@@ -42,7 +49,7 @@ func (l *Log) GetLogWriter(target io.Writer) loggo.Writer {
 // AddFlags adds appropriate flags to f.
 func (l *Log) AddFlags(f *gnuflag.FlagSet) {
 	f.StringVar(&l.Path, "log-file", "", "path to write log to")
-	f.BoolVar(&l.Verbose, "v", false, "show more verbose output")
+	f.Var(&verbosityValue{l}, "v", "show more verbose output and info logging (-vv and -vvv show debug and trace logging)")
 	f.BoolVar(&l.Verbose, "verbose", false, "show more verbose output")
 	f.BoolVar(&l.Quiet, "q", false, "show no informational output")
 	f.BoolVar(&l.Quiet, "quiet", false, "show no informational output")
@@ -56,8 +63,12 @@ func (log *Log) Start(ctx *Context) error {
 	if log.Verbose && log.Quiet {
 		return fmt.Errorf(`"verbose" and "quiet" flags clash, please use one or the other, not both`)
 	}
+	verbosity := log.Verbosity
+	if log.Verbose && verbosity == 0 {
+		verbosity = 1
+	}
 	ctx.quiet = log.Quiet
-	ctx.verbose = log.Verbose
+	ctx.verbosity = verbosity
 	if log.Path != "" {
 		path := ctx.AbsPath(log.Path)
 		target, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
@@ -74,18 +85,29 @@ func (log *Log) Start(ctx *Context) error {
 	if log.ShowLog {
 		level = loggo.INFO
 	}
+	switch {
+	case log.Verbosity >= 3:
+		log.ShowLog = true
+		level = loggo.TRACE
+	case log.Verbosity == 2:
+		log.ShowLog = true
+		level = loggo.DEBUG
+	case log.Verbosity == 1:
+		log.ShowLog = true
+		level = loggo.INFO
+	}
 	if log.Debug {
 		log.ShowLog = true
 		level = loggo.DEBUG
 		// override quiet or verbose if set, this way all the information goes
 		// to the log file.
 		ctx.quiet = true
-		ctx.verbose = false
+		ctx.verbosity = 0
 	}
 
 	if log.ShowLog {
 		// We replace the default writer to use ctx.Stderr rather than os.Stderr.
-		writer := log.GetLogWriter(ctx.Stderr)
+		writer := ctx.progressLogWriter(log.GetLogWriter(ctx.Stderr))
 		_, err := loggo.ReplaceDefaultWriter(writer)
 		if err != nil {
 			return err
@@ -94,7 +116,7 @@ func (log *Log) Start(ctx *Context) error {
 		loggo.RemoveWriter("default")
 		// Create a simple writer that doesn't show filenames, or timestamps,
 		// and only shows warning or above.
-		writer := NewWarningWriter(ctx.Stderr)
+		writer := ctx.progressLogWriter(NewWarningWriter(ctx.Stderr))
 		err := loggo.RegisterWriter("warning", writer)
 		if err != nil {
 			return err
@@ -108,6 +130,37 @@ func (log *Log) Start(ctx *Context) error {
 	return nil
 }
 
+// verbosityValue implements gnuflag.Value for the -v flag, counting in
+// Log.Verbosity and setting Log.Verbose.
+type verbosityValue struct {
+	log *Log
+}
+
+// Set implements gnuflag.Value.
+func (v *verbosityValue) Set(s string) error {
+	if err := (*CountValue)(&v.log.Verbosity).Set(s); err != nil {
+		return err
+	}
+	if v.log.Verbosity > 0 {
+		v.log.Verbose = true
+	}
+	return nil
+}
+
+// String implements gnuflag.Value. It shows no verbosity as "false", as
+// it was shown when -v was a boolean flag.
+func (v *verbosityValue) String() string {
+	if v.log.Verbosity == 0 {
+		return "false"
+	}
+	return (*CountValue)(&v.log.Verbosity).String()
+}
+
+// IsBoolFlag tells gnuflag that the flag does not take an argument.
+func (v *verbosityValue) IsBoolFlag() bool {
+	return true
+}
+
 // NewCommandLogWriter creates a loggo writer for registration
 // by the callers of a command. This way the logged output can also
 // be displayed otherwise, e.g. on the screen.
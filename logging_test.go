@@ -52,6 +52,27 @@ func (s *LogSuite) TestFlags(c *gc.C) {
 	c.Assert(log.Config, gc.Equals, "juju.cmd=INFO;juju.worker.deployer=DEBUG")
 }
 
+func (s *LogSuite) TestVerbosityFlags(c *gc.C) {
+	for i, test := range []struct {
+		flags     []string
+		verbose   bool
+		verbosity int
+	}{
+		{nil, false, 0},
+		{[]string{"-v"}, true, 1},
+		{[]string{"--verbose"}, true, 0},
+		{[]string{"-vv"}, true, 2},
+		{[]string{"-vvv"}, true, 3},
+		{[]string{"-v", "--verbose", "-v"}, true, 2},
+		{[]string{"-vv", "--verbose=false"}, false, 2},
+	} {
+		c.Logf("test %d: %q", i, test.flags)
+		log := newLogWithFlags(c, "", test.flags...)
+		c.Check(log.Verbose, gc.Equals, test.verbose)
+		c.Check(log.Verbosity, gc.Equals, test.verbosity)
+	}
+}
+
 func (s *LogSuite) TestLogConfigFromDefault(c *gc.C) {
 	config := "juju.cmd=INFO;juju.worker.deployer=DEBUG"
 	log := newLogWithFlags(c, config)
@@ -73,6 +94,58 @@ func (s *LogSuite) TestDebugSetsLogLevel(c *gc.C) {
 	c.Assert(cmdtesting.Stdout(ctx), gc.Equals, "")
 }
 
+func (s *LogSuite) TestVerbositySetsLogLevel(c *gc.C) {
+	for i, test := range []struct {
+		log       cmd.Log
+		level     loggo.Level
+		verbosity int
+	}{
+		{cmd.Log{Verbose: true, Verbosity: 1}, loggo.INFO, 1},
+		{cmd.Log{Verbose: true, Verbosity: 2}, loggo.DEBUG, 2},
+		{cmd.Log{Verbose: true, Verbosity: 3}, loggo.TRACE, 3},
+		{cmd.Log{Verbose: true, Verbosity: 5}, loggo.TRACE, 5},
+	} {
+		c.Logf("test %d", i)
+		ctx := cmdtesting.Context(c)
+		err := test.log.Start(ctx)
+		c.Assert(err, gc.IsNil)
+		c.Check(loggo.GetLogger("").LogLevel(), gc.Equals, test.level)
+		c.Check(ctx.Verbosity(), gc.Equals, test.verbosity)
+		c.Check(test.log.ShowLog, gc.Equals, true)
+	}
+}
+
+func (s *LogSuite) TestVerboseKeepsLogLevel(c *gc.C) {
+	l := newLogWithFlags(c, "", "--verbose")
+	ctx := cmdtesting.Context(c)
+	err := l.Start(ctx)
+	c.Assert(err, gc.IsNil)
+	c.Check(loggo.GetLogger("").LogLevel(), gc.Equals, loggo.WARNING)
+	c.Check(ctx.Verbosity(), gc.Equals, 1)
+	c.Check(l.ShowLog, gc.Equals, false)
+	logger.Infof("informing")
+	ctx.Verbosef("Writing verbose output")
+	c.Assert(cmdtesting.Stderr(ctx), gc.Equals, "Writing verbose output\n")
+}
+
+func (s *LogSuite) TestVerbosityHelp(c *gc.C) {
+	f := cmdtesting.NewFlagSet()
+	(&cmd.Log{}).AddFlags(f)
+	c.Assert(f.Lookup("v").DefValue, gc.Equals, "false")
+	c.Assert(f.Lookup("verbose").DefValue, gc.Equals, "false")
+}
+
+func (s *LogSuite) TestVerboseShowsInfoOnStderr(c *gc.C) {
+	l := newLogWithFlags(c, "", "-vv")
+	ctx := cmdtesting.Context(c)
+	err := l.Start(ctx)
+	c.Assert(err, gc.IsNil)
+	logger.Debugf("debugging")
+	logger.Tracef("tracing")
+	ctx.Verbosef("Writing verbose output")
+	c.Assert(cmdtesting.Stderr(ctx), gc.Matches, `^.* DEBUG .* debugging\nWriting verbose output\n$`)
+}
+
 func (s *LogSuite) TestShowLogSetsLogLevel(c *gc.C) {
 	l := &cmd.Log{ShowLog: true}
 	ctx := cmdtesting.Context(c)
@@ -176,6 +249,14 @@ func (s *LogSuite) TestOutputVerbose(c *gc.C) {
 	c.Assert(cmdtesting.Stderr(ctx), gc.Equals, "Writing info output\nWriting verbose output\n")
 }
 
+func (s *LogSuite) TestOutputDefaultVerbosity(c *gc.C) {
+	l := &cmd.Log{}
+	ctx := cmdtesting.Context(c)
+	err := l.Start(ctx)
+	c.Assert(err, gc.IsNil)
+	c.Assert(ctx.Verbosity(), gc.Equals, 0)
+}
+
 func (s *LogSuite) TestOutputQuiet(c *gc.C) {
 	l := &cmd.Log{Quiet: true}
 	ctx := cmdtesting.Context(c)
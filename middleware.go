@@ -0,0 +1,165 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENSE file for details.
+
+package cmd
+
+import (
+	"strings"
+	"time"
+
+	"github.com/juju/gnuflag"
+)
+
+// Phase identifies the stage of a subcommand's execution that a
+// Middleware is wrapping.
+type Phase string
+
+const (
+	// InitPhase is the subcommand's Init call.
+	InitPhase Phase = "init"
+
+	// RunPhase is the subcommand's Run call.
+	RunPhase Phase = "run"
+)
+
+// Invocation describes a call to a subcommand's Init or Run method, as
+// seen by a Middleware. The fields after Phase are filled in when the
+// wrapped call returns.
+type Invocation struct {
+	// Path holds the names of the commands that lead to the subcommand,
+	// starting with the outermost SuperCommand, for example
+	// []string{"juju", "cloud", "add"}.
+	Path []string
+
+	// Command holds the subcommand itself.
+	Command Command
+
+	// Flags holds the flag set that the subcommand's arguments were
+	// parsed with. It is only fully parsed in the run phase.
+	Flags *gnuflag.FlagSet
+
+	// Args holds the positional arguments passed to the subcommand's
+	// Init method.
+	Args []string
+
+	// Context holds the context the subcommand is run in. It is nil in
+	// the init phase.
+	Context *Context
+
+	// Phase holds the call being made.
+	Phase Phase
+
+	// Elapsed holds the time taken by the call.
+	Elapsed time.Duration
+
+	// Err holds the error returned by the call.
+	Err error
+
+	// Code holds the exit code that Main will return for Err. It is
+	// PanicExitCode if the call panicked, in which case Err describes
+	// the panic.
+	Code int
+}
+
+// Middleware wraps the Init and Run calls of every subcommand of a
+// SuperCommand, including those of nested SuperCommands. It must call
+// next to make the call, and should usually return its error. If the call
+// panics, next does not return, but the Invocation is filled in before
+// the panic continues, so middleware that reports from a deferred
+// function still sees it.
+type Middleware func(inv *Invocation, next func() error) error
+
+// commandPath returns the names of the commands that lead to c.
+func (c *SuperCommand) commandPath() []string {
+	if c.parentPath != nil {
+		return append(c.parentPath[:len(c.parentPath):len(c.parentPath)], c.Name)
+	}
+	if c.parent != nil {
+		return append(c.parent.commandPath(), c.Name)
+	}
+	var path []string
+	if c.usagePrefix != "" && c.usagePrefix != c.Name {
+		path = strings.Fields(c.usagePrefix)
+	}
+	return append(path, c.Name)
+}
+
+// invoke calls f, which makes the given call on the selected subcommand,
+// through c's middleware. Calls on a nested SuperCommand are made
+// directly, as it applies the middleware to its own subcommands.
+func (c *SuperCommand) invoke(phase Phase, ctx *Context, args []string, f func() error) error {
+	if phase == InitPhase {
+		c.actionArgs = args
+	}
+	subcmd := c.action.command
+	if nested, ok := subcmd.(*SuperCommand); ok {
+		nested.parentPath = c.commandPath()
+		nested.parentMiddleware = c.allMiddleware()
+		return f()
+	}
+	middleware := c.allMiddleware()
+	if len(middleware) == 0 {
+		return f()
+	}
+	inv := &Invocation{
+		Path:    append(c.commandPath(), c.action.name),
+		Command: subcmd,
+		Flags:   c.commonflags,
+		Args:    args,
+		Context: ctx,
+		Phase:   phase,
+	}
+	next := func() error {
+		start := time.Now()
+		defer func() {
+			if v := recover(); v != nil {
+				// Record the panic and let it continue from
+				// here, where its stack is intact, for Main
+				// to report.
+				inv.Elapsed = time.Since(start)
+				inv.Err = &panicError{value: v}
+				inv.Code = PanicExitCode
+				panic(v)
+			}
+		}()
+		err := f()
+		inv.Elapsed = time.Since(start)
+		inv.Err = err
+		inv.Code = exitCode(phase, err)
+		return err
+	}
+	for i := len(middleware) - 1; i >= 0; i-- {
+		m, inner := middleware[i], next
+		next = func() error {
+			return m(inv, inner)
+		}
+	}
+	return next()
+}
+
+// allMiddleware returns the middleware inherited from any parent
+// SuperCommand followed by c's own.
+func (c *SuperCommand) allMiddleware() []Middleware {
+	if len(c.parentMiddleware) == 0 {
+		return c.middleware
+	}
+	all := append([]Middleware(nil), c.parentMiddleware...)
+	return append(all, c.middleware...)
+}
+
+// exitCode returns the code that Main returns when the given phase of a
+// command fails with err.
+func exitCode(phase Phase, err error) int {
+	switch {
+	case err == nil:
+		return 0
+	case phase == InitPhase:
+		if err == gnuflag.ErrHelp {
+			return 0
+		}
+		return 2
+	case IsRcPassthroughError(err):
+		return err.(*RcPassthroughError).Code
+	}
+	return 1
+}
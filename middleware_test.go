@@ -0,0 +1,222 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENSE file for details.
+
+package cmd_test
+
+import (
+	"fmt"
+
+	"github.com/juju/gnuflag"
+	"github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/cmd"
+	"github.com/juju/cmd/cmdtesting"
+)
+
+type MiddlewareSuite struct {
+	testing.IsolationSuite
+}
+
+var _ = gc.Suite(&MiddlewareSuite{})
+
+// recorder returns a middleware that records each invocation it sees in
+// calls, tagged with name.
+func recorder(name string, calls *[]string) cmd.Middleware {
+	return func(inv *cmd.Invocation, next func() error) error {
+		*calls = append(*calls, fmt.Sprintf("%s: before %s %v %q", name, inv.Phase, inv.Path, inv.Args))
+		err := next()
+		*calls = append(*calls, fmt.Sprintf("%s: after %s %v code %d err %v", name, inv.Phase, inv.Path, inv.Code, inv.Err))
+		return err
+	}
+}
+
+func (s *MiddlewareSuite) TestOrder(c *gc.C) {
+	var calls []string
+	super := cmd.NewSuperCommand(cmd.SuperCommandParams{
+		Name:       "jujutest",
+		Middleware: []cmd.Middleware{recorder("a", &calls), recorder("b", &calls)},
+	})
+	super.Register(&TestCommand{Name: "blah"})
+	ctx := cmdtesting.Context(c)
+	code := cmd.Main(super, ctx, []string{"blah", "--option", "error"})
+	c.Assert(code, gc.Equals, 1)
+	c.Assert(calls, jc.DeepEquals, []string{
+		`a: before init [jujutest blah] []`,
+		`b: before init [jujutest blah] []`,
+		`b: after init [jujutest blah] code 0 err <nil>`,
+		`a: after init [jujutest blah] code 0 err <nil>`,
+		`a: before run [jujutest blah] []`,
+		`b: before run [jujutest blah] []`,
+		`b: after run [jujutest blah] code 1 err BAM!`,
+		`a: after run [jujutest blah] code 1 err BAM!`,
+	})
+}
+
+func (s *MiddlewareSuite) TestInitError(c *gc.C) {
+	var calls []string
+	super := cmd.NewSuperCommand(cmd.SuperCommandParams{
+		Name:       "jujutest",
+		Middleware: []cmd.Middleware{recorder("a", &calls)},
+	})
+	super.Register(&TestCommand{Name: "blah"})
+	ctx := cmdtesting.Context(c)
+	code := cmd.Main(super, ctx, []string{"blah", "extra"})
+	c.Assert(code, gc.Equals, 2)
+	c.Assert(calls, jc.DeepEquals, []string{
+		`a: before init [jujutest blah] ["extra"]`,
+		`a: after init [jujutest blah] code 2 err unrecognized args: ["extra"]`,
+	})
+}
+
+func (s *MiddlewareSuite) TestNested(c *gc.C) {
+	var calls []string
+	super := cmd.NewSuperCommand(cmd.SuperCommandParams{
+		Name:       "juju",
+		Middleware: []cmd.Middleware{recorder("outer", &calls)},
+	})
+	nested := cmd.NewSuperCommand(cmd.SuperCommandParams{
+		Name:        "cloud",
+		UsagePrefix: "juju",
+		Middleware:  []cmd.Middleware{recorder("inner", &calls)},
+	})
+	nested.Register(&TestCommand{Name: "add"})
+	super.Register(nested)
+	ctx := cmdtesting.Context(c)
+	code := cmd.Main(super, ctx, []string{"cloud", "add", "--option", "ok"})
+	c.Assert(code, gc.Equals, 0)
+	c.Assert(cmdtesting.Stdout(ctx), gc.Equals, "ok\n")
+	c.Assert(calls, jc.DeepEquals, []string{
+		`outer: before init [juju cloud add] []`,
+		`inner: before init [juju cloud add] []`,
+		`inner: after init [juju cloud add] code 0 err <nil>`,
+		`outer: after init [juju cloud add] code 0 err <nil>`,
+		`outer: before run [juju cloud add] []`,
+		`inner: before run [juju cloud add] []`,
+		`inner: after run [juju cloud add] code 0 err <nil>`,
+		`outer: after run [juju cloud add] code 0 err <nil>`,
+	})
+}
+
+func (s *MiddlewareSuite) TestSeesFlagsAndContext(c *gc.C) {
+	var option string
+	var sawContext bool
+	middleware := func(inv *cmd.Invocation, next func() error) error {
+		if inv.Phase == cmd.RunPhase {
+			option = inv.Flags.Lookup("option").Value.String()
+			sawContext = inv.Context != nil
+		}
+		return next()
+	}
+	super := cmd.NewSuperCommand(cmd.SuperCommandParams{
+		Name:       "jujutest",
+		Middleware: []cmd.Middleware{middleware},
+	})
+	super.Register(&TestCommand{Name: "blah"})
+	ctx := cmdtesting.Context(c)
+	code := cmd.Main(super, ctx, []string{"blah", "--option", "value"})
+	c.Assert(code, gc.Equals, 0)
+	c.Assert(option, gc.Equals, "value")
+	c.Assert(sawContext, jc.IsTrue)
+}
+
+func (s *MiddlewareSuite) TestCanAbort(c *gc.C) {
+	middleware := func(inv *cmd.Invocation, next func() error) error {
+		if inv.Phase == cmd.RunPhase {
+			return cmd.NewRcPassthroughError(3)
+		}
+		return next()
+	}
+	super := cmd.NewSuperCommand(cmd.SuperCommandParams{
+		Name:       "jujutest",
+		Middleware: []cmd.Middleware{middleware},
+	})
+	super.Register(&TestCommand{Name: "blah"})
+	ctx := cmdtesting.Context(c)
+	code := cmd.Main(super, ctx, []string{"blah", "--option", "value"})
+	c.Assert(code, gc.Equals, 3)
+	c.Assert(cmdtesting.Stdout(ctx), gc.Equals, "")
+}
+
+// failingValue is a flag value that cannot be resolved.
+type failingValue struct {
+	value string
+}
+
+func (v *failingValue) Set(s string) error {
+	v.value = s
+	return nil
+}
+
+func (v *failingValue) String() string {
+	return v.value
+}
+
+func (v *failingValue) ResolveFlag(ctx *cmd.Context) error {
+	return fmt.Errorf("cannot resolve %q", v.value)
+}
+
+type resolveCommand struct {
+	cmd.CommandBase
+	value failingValue
+}
+
+func (c *resolveCommand) Info() *cmd.Info {
+	return &cmd.Info{Name: "resolve"}
+}
+
+func (c *resolveCommand) SetFlags(f *gnuflag.FlagSet) {
+	f.Var(&c.value, "value", "")
+}
+
+func (c *resolveCommand) Run(ctx *cmd.Context) error {
+	return nil
+}
+
+func (s *MiddlewareSuite) TestSeesResolveError(c *gc.C) {
+	var calls []string
+	super := cmd.NewSuperCommand(cmd.SuperCommandParams{
+		Name:       "jujutest",
+		Middleware: []cmd.Middleware{recorder("a", &calls)},
+	})
+	super.Register(&resolveCommand{})
+	ctx := cmdtesting.Context(c)
+	code := cmd.Main(super, ctx, []string{"resolve", "--value", "x"})
+	c.Assert(code, gc.Equals, 1)
+	c.Assert(calls, jc.DeepEquals, []string{
+		`a: before init [jujutest resolve] []`,
+		`a: after init [jujutest resolve] code 0 err <nil>`,
+		`a: before run [jujutest resolve] []`,
+		`a: after run [jujutest resolve] code 1 err invalid value "x" for flag --value: cannot resolve "x"`,
+	})
+	c.Assert(cmdtesting.Stderr(ctx), gc.Equals, `ERROR invalid value "x" for flag --value: cannot resolve "x"`+"\n")
+}
+
+func (s *MiddlewareSuite) TestMissingCommand(c *gc.C) {
+	var calls []string
+	var names []string
+	named := func(inv *cmd.Invocation, next func() error) error {
+		names = append(names, inv.Command.Info().Name)
+		return next()
+	}
+	super := cmd.NewSuperCommand(cmd.SuperCommandParams{
+		Name:       "jujutest",
+		Middleware: []cmd.Middleware{recorder("a", &calls), named},
+		MissingCallback: func(ctx *cmd.Context, subcommand string, args []string) error {
+			fmt.Fprintf(ctx.Stdout, "%s %q\n", subcommand, args)
+			return nil
+		},
+	})
+	ctx := cmdtesting.Context(c)
+	code := cmd.Main(super, ctx, []string{"plugin", "arg"})
+	c.Assert(code, gc.Equals, 0)
+	c.Assert(calls, jc.DeepEquals, []string{
+		`a: before init [jujutest plugin] ["arg"]`,
+		`a: after init [jujutest plugin] code 0 err <nil>`,
+		`a: before run [jujutest plugin] ["arg"]`,
+		`a: after run [jujutest plugin] code 0 err <nil>`,
+	})
+	c.Assert(names, jc.DeepEquals, []string{"plugin", "plugin"})
+	c.Assert(cmdtesting.Stdout(ctx), gc.Equals, `plugin ["arg"]`+"\n")
+}
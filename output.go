@@ -95,6 +95,7 @@ var DefaultFormatters = map[string]Formatter{
 
 // formatterValue implements gnuflag.Value for the --format flag.
 type formatterValue struct {
+	*EnumValue
 	name       string
 	formatters map[string]Formatter
 }
@@ -102,37 +103,25 @@ type formatterValue struct {
 // newFormatterValue returns a new formatterValue. The initial Formatter name
 // must be present in formatters.
 func newFormatterValue(initial string, formatters map[string]Formatter) *formatterValue {
+	choices := make([]string, 0, len(formatters))
+	for name := range formatters {
+		choices = append(choices, name)
+	}
+	sort.Strings(choices)
 	v := &formatterValue{formatters: formatters}
+	v.EnumValue = &EnumValue{
+		Target:  &v.name,
+		Choices: choices,
+	}
 	if err := v.Set(initial); err != nil {
 		panic(err)
 	}
 	return v
 }
 
-// Set stores the chosen formatter name in v.name.
-func (v *formatterValue) Set(value string) error {
-	if v.formatters[value] == nil {
-		return fmt.Errorf("unknown format %q", value)
-	}
-	v.name = value
-	return nil
-}
-
-// String returns the chosen formatter name.
-func (v *formatterValue) String() string {
-	return v.name
-}
-
 // doc returns documentation for the --format flag.
 func (v *formatterValue) doc() string {
-	choices := make([]string, len(v.formatters))
-	i := 0
-	for name := range v.formatters {
-		choices[i] = name
-		i++
-	}
-	sort.Strings(choices)
-	return "Specify output format (" + strings.Join(choices, "|") + ")"
+	return v.Doc("Specify output format")
 }
 
 // format runs the chosen formatter on value.
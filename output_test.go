@@ -150,7 +150,7 @@ func (s *CmdSuite) TestUnknownOutputFormat(c *gc.C) {
 	result := cmd.Main(&OutputCommand{}, ctx, []string{"--format", "cuneiform"})
 	c.Check(result, gc.Equals, 2)
 	c.Check(bufferString(ctx.Stdout), gc.Equals, "")
-	c.Check(bufferString(ctx.Stderr), gc.Matches, ".*: unknown format \"cuneiform\"\n")
+	c.Check(bufferString(ctx.Stderr), gc.Matches, ".*: expected one of: json, smart, yaml\n")
 }
 
 // Py juju allowed both --format json and --format=json. This test verifies that juju is
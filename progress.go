@@ -0,0 +1,493 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENSE file for details.
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/juju/loggo"
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+// progressDisplay draws progress items to a Context's Stderr. On a
+// terminal, the items are redrawn in place below any other output;
+// otherwise each item writes occasional plain lines.
+type progressDisplay struct {
+	mu  sync.Mutex
+	out io.Writer
+
+	// terminal holds whether out is a terminal.
+	terminal bool
+
+	// columns holds the width of the terminal if out is not a file,
+	// or 0 if lines should not be truncated.
+	columns int
+
+	// animate holds whether spinners should be animated.
+	animate bool
+
+	// items holds the items currently displayed.
+	items []progressItem
+
+	// drawn holds the number of lines last drawn.
+	drawn int
+}
+
+// progressItem is implemented by the things drawn by a progressDisplay.
+type progressItem interface {
+	// lines returns the lines that currently represent the item.
+	lines() []string
+
+	// finished reports whether the item will not change again.
+	finished() bool
+}
+
+// progressDisplay returns the progress display for ctx, creating it if
+// necessary.
+func (ctx *Context) progressDisplay() *progressDisplay {
+	ctx.progressOnce.Do(func() {
+		isTerminal := isTerminalWriter(ctx.Stderr)
+		ctx.progress = &progressDisplay{
+			out:      ctx.Stderr,
+			terminal: isTerminal,
+			animate:  isTerminal,
+		}
+	})
+	return ctx.progress
+}
+
+// isTerminalWriter reports whether w is a terminal that understands
+// cursor movement.
+func isTerminalWriter(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	return ok && os.Getenv("TERM") != "dumb" && terminal.IsTerminal(int(f.Fd()))
+}
+
+// addProgress starts displaying item. It returns nil if the context is
+// quiet, in which case no progress is shown.
+func (ctx *Context) addProgress(item progressItem) *progressDisplay {
+	if ctx.quiet {
+		return nil
+	}
+	d := ctx.progressDisplay()
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.items = append(d.items, item)
+	d.redrawLocked()
+	return d
+}
+
+// redrawLocked draws all the items again, replacing the previous lines.
+// Once all the items are finished, they are left on the screen and
+// forgotten. It must be called with d.mu held.
+func (d *progressDisplay) redrawLocked() {
+	if !d.terminal {
+		return
+	}
+	d.clearLocked()
+	// Lines are truncated so that none wraps, as the cursor is moved
+	// back up by the number of lines drawn.
+	width := d.width()
+	finished := true
+	for _, item := range d.items {
+		for _, line := range item.lines() {
+			fmt.Fprintf(d.out, "%s\n", truncateLine(line, width))
+			d.drawn++
+		}
+		finished = finished && item.finished()
+	}
+	if finished {
+		d.items = nil
+		d.drawn = 0
+	}
+}
+
+// width returns the number of columns of the terminal, or 0 if it is not
+// known.
+func (d *progressDisplay) width() int {
+	if f, ok := d.out.(*os.File); ok {
+		if width, _, err := terminal.GetSize(int(f.Fd())); err == nil {
+			return width
+		}
+	}
+	return d.columns
+}
+
+// truncateLine returns line cut short to fit in fewer than width columns,
+// so that writing it does not move the cursor to another line. A width
+// of 0 or less leaves line as it is.
+func truncateLine(line string, width int) string {
+	if width <= 0 || displayWidth(line) < width {
+		return line
+	}
+	used := 0
+	for i, r := range line {
+		if used+runeWidth(r) >= width {
+			return line[:i]
+		}
+		used += runeWidth(r)
+	}
+	return line
+}
+
+// clearLocked removes the lines last drawn from the screen, leaving the
+// cursor where the first of them was. It must be called with d.mu held.
+func (d *progressDisplay) clearLocked() {
+	if d.drawn > 0 {
+		fmt.Fprintf(d.out, "\x1b[%dA\x1b[J", d.drawn)
+		d.drawn = 0
+	}
+}
+
+// printLocked writes a plain line of progress when not on a terminal. It
+// must be called with d.mu held.
+func (d *progressDisplay) printLocked(format string, args ...interface{}) {
+	if !d.terminal {
+		fmt.Fprintf(d.out, format+"\n", args...)
+	}
+}
+
+// progressLogWriter wraps a loggo.Writer that writes to the same stream as
+// a progressDisplay, so that log lines are written above the progress
+// items rather than in the middle of them.
+type progressLogWriter struct {
+	display *progressDisplay
+	writer  loggo.Writer
+}
+
+// progressLogWriter returns a loggo.Writer that writes to writer, which
+// must write to ctx.Stderr, without disturbing any progress items.
+func (ctx *Context) progressLogWriter(writer loggo.Writer) loggo.Writer {
+	return &progressLogWriter{ctx.progressDisplay(), writer}
+}
+
+// Write implements loggo.Writer.
+func (w *progressLogWriter) Write(entry loggo.Entry) {
+	d := w.display
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.clearLocked()
+	w.writer.Write(entry)
+	d.redrawLocked()
+}
+
+const progressBarWidth = 30
+
+// ProgressBar shows the progress of an operation of known size, such as an
+// upload. It is safe for concurrent use.
+type ProgressBar struct {
+	display *progressDisplay
+	label   string
+	total   int64
+	current int64
+	done    bool
+
+	// reported holds the number of quarters reported when not on a
+	// terminal.
+	reported int64
+
+	// drawn holds the percentage last drawn on a terminal.
+	drawn int64
+}
+
+// NewProgressBar starts showing a progress bar with the given label on
+// Stderr, for an operation that will reach total. Nothing is shown if the
+// context is quiet. Done must be called when the operation is complete.
+func (ctx *Context) NewProgressBar(label string, total int64) *ProgressBar {
+	b := &ProgressBar{label: label, total: total}
+	b.display = ctx.addProgress(b)
+	if b.display != nil {
+		b.display.mu.Lock()
+		b.display.printLocked("%s: 0%%", b.label)
+		b.display.mu.Unlock()
+	}
+	return b
+}
+
+// Set records the current progress.
+func (b *ProgressBar) Set(current int64) {
+	b.update(func() { b.current = current })
+}
+
+// Add adds n to the current progress.
+func (b *ProgressBar) Add(n int64) {
+	b.update(func() { b.current += n })
+}
+
+// Write implements io.Writer by adding len(p) to the current progress,
+// so that the bar can follow an io.Copy through io.MultiWriter or
+// io.TeeReader.
+func (b *ProgressBar) Write(p []byte) (int, error) {
+	b.Add(int64(len(p)))
+	return len(p), nil
+}
+
+// Done marks the operation as complete.
+func (b *ProgressBar) Done() {
+	b.update(func() {
+		b.done = true
+		b.current = b.total
+		b.display.printLocked("%s: done", b.label)
+	})
+}
+
+// update calls f with the display locked and then shows the result.
+func (b *ProgressBar) update(f func()) {
+	if b.display == nil {
+		return
+	}
+	b.display.mu.Lock()
+	defer b.display.mu.Unlock()
+	if b.done {
+		return
+	}
+	f()
+	percent := b.percent()
+	if quarter := percent / 25; !b.done && quarter > b.reported && quarter < 4 {
+		b.reported = quarter
+		b.display.printLocked("%s: %d%%", b.label, quarter*25)
+	}
+	// The bar only changes with the percentage, so there is no need to
+	// redraw it for every write of an io.Copy.
+	if !b.done && percent == b.drawn {
+		return
+	}
+	b.drawn = percent
+	b.display.redrawLocked()
+}
+
+// percent returns the percentage of the operation that is complete.
+func (b *ProgressBar) percent() int64 {
+	switch {
+	case b.total <= 0 || b.current <= 0:
+		return 0
+	case b.current >= b.total:
+		return 100
+	}
+	return b.current * 100 / b.total
+}
+
+func (b *ProgressBar) lines() []string {
+	percent := b.percent()
+	filled := int(percent * progressBarWidth / 100)
+	bar := strings.Repeat("=", filled) + strings.Repeat(" ", progressBarWidth-filled)
+	return []string{fmt.Sprintf("%s [%s] %3d%%", b.label, bar, percent)}
+}
+
+func (b *ProgressBar) finished() bool {
+	return b.done
+}
+
+var spinnerFrames = []string{"|", "/", "-", "\\"}
+
+// Spinner shows that an operation of unknown length is still running. It
+// is safe for concurrent use.
+type Spinner struct {
+	display *progressDisplay
+	label   string
+	status  string
+	frame   int
+	done    bool
+	stop    chan struct{}
+}
+
+// NewSpinner starts showing a spinner with the given label on Stderr.
+// Nothing is shown if the context is quiet. Done must be called when the
+// operation is complete.
+func (ctx *Context) NewSpinner(label string) *Spinner {
+	s := &Spinner{label: label, stop: make(chan struct{})}
+	s.display = ctx.addProgress(s)
+	if s.display == nil {
+		return s
+	}
+	s.display.mu.Lock()
+	s.display.printLocked("%s...", s.label)
+	animate := s.display.animate
+	s.display.mu.Unlock()
+	if animate {
+		go s.loop()
+	}
+	return s
+}
+
+// loop animates the spinner until it is done.
+func (s *Spinner) loop() {
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+			s.display.mu.Lock()
+			s.frame++
+			s.display.redrawLocked()
+			s.display.mu.Unlock()
+		}
+	}
+}
+
+// Update sets the status shown next to the spinner. It is only shown on
+// a terminal.
+func (s *Spinner) Update(status string) {
+	if s.display == nil {
+		return
+	}
+	s.display.mu.Lock()
+	defer s.display.mu.Unlock()
+	if s.done {
+		return
+	}
+	s.status = status
+	s.frame++
+	s.display.redrawLocked()
+}
+
+// Done stops the spinner, leaving the given result in its place.
+func (s *Spinner) Done(result string) {
+	if s.display == nil {
+		return
+	}
+	s.display.mu.Lock()
+	defer s.display.mu.Unlock()
+	if s.done {
+		return
+	}
+	s.done = true
+	s.status = result
+	close(s.stop)
+	s.display.printLocked("%s: %s", s.label, result)
+	s.display.redrawLocked()
+}
+
+func (s *Spinner) lines() []string {
+	if s.done {
+		return []string{fmt.Sprintf("%s: %s", s.label, s.status)}
+	}
+	line := s.label + " " + spinnerFrames[s.frame%len(spinnerFrames)]
+	if s.status != "" {
+		line += " " + s.status
+	}
+	return []string{line}
+}
+
+func (s *Spinner) finished() bool {
+	return s.done
+}
+
+// TaskList shows the state of a number of tasks, one per line. It is safe
+// for concurrent use.
+type TaskList struct {
+	display *progressDisplay
+	tasks   []*Task
+	closed  bool
+}
+
+// Task is a single entry in a TaskList.
+type Task struct {
+	list  *TaskList
+	name  string
+	state taskState
+	err   error
+}
+
+type taskState int
+
+const (
+	taskPending taskState = iota
+	taskRunning
+	taskDone
+	taskFailed
+)
+
+var taskMarkers = map[taskState]string{
+	taskPending: "[ ]",
+	taskRunning: "[-]",
+	taskDone:    "[x]",
+	taskFailed:  "[!]",
+}
+
+// NewTaskList starts showing a list of tasks on Stderr. Nothing is shown
+// if the context is quiet. Close must be called when no more tasks will
+// change.
+func (ctx *Context) NewTaskList() *TaskList {
+	l := &TaskList{}
+	l.display = ctx.addProgress(l)
+	return l
+}
+
+// Add adds a pending task to the list.
+func (l *TaskList) Add(name string) *Task {
+	t := &Task{list: l, name: name}
+	l.update(func() {
+		l.tasks = append(l.tasks, t)
+	})
+	return t
+}
+
+// Close marks the list as complete.
+func (l *TaskList) Close() {
+	l.update(func() {
+		l.closed = true
+	})
+}
+
+// Start marks the task as running.
+func (t *Task) Start() {
+	t.list.update(func() {
+		t.state = taskRunning
+		t.list.display.printLocked("%s: started", t.name)
+	})
+}
+
+// Done marks the task as successfully completed.
+func (t *Task) Done() {
+	t.list.update(func() {
+		t.state = taskDone
+		t.list.display.printLocked("%s: done", t.name)
+	})
+}
+
+// Fail marks the task as failed with the given error.
+func (t *Task) Fail(err error) {
+	t.list.update(func() {
+		t.state = taskFailed
+		t.err = err
+		t.list.display.printLocked("%s: failed: %v", t.name, err)
+	})
+}
+
+// update calls f with the display locked and then shows the result.
+func (l *TaskList) update(f func()) {
+	if l.display == nil {
+		return
+	}
+	l.display.mu.Lock()
+	defer l.display.mu.Unlock()
+	if l.closed {
+		return
+	}
+	f()
+	l.display.redrawLocked()
+}
+
+func (l *TaskList) lines() []string {
+	lines := make([]string, len(l.tasks))
+	for i, t := range l.tasks {
+		lines[i] = taskMarkers[t.state] + " " + t.name
+		if t.err != nil {
+			lines[i] += ": " + t.err.Error()
+		}
+	}
+	return lines
+}
+
+func (l *TaskList) finished() bool {
+	return l.closed
+}
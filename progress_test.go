@@ -0,0 +1,183 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENSE file for details.
+
+package cmd_test
+
+import (
+	"errors"
+	"io"
+	"strings"
+
+	"github.com/juju/loggo"
+	"github.com/juju/testing"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/cmd"
+	"github.com/juju/cmd/cmdtesting"
+)
+
+type ProgressSuite struct {
+	testing.LoggingCleanupSuite
+}
+
+var _ = gc.Suite(&ProgressSuite{})
+
+func (s *ProgressSuite) TestProgressBarPiped(c *gc.C) {
+	ctx := cmdtesting.Context(c)
+	bar := ctx.NewProgressBar("uploading", 100)
+	bar.Set(10)
+	bar.Set(30)
+	bar.Set(40)
+	_, err := io.Copy(bar, strings.NewReader(strings.Repeat("x", 35)))
+	c.Assert(err, gc.IsNil)
+	bar.Add(20)
+	bar.Done()
+	bar.Add(1)
+	c.Assert(cmdtesting.Stderr(ctx), gc.Equals, `
+uploading: 0%
+uploading: 25%
+uploading: 75%
+uploading: done
+`[1:])
+}
+
+func (s *ProgressSuite) TestProgressBarTerminal(c *gc.C) {
+	ctx := cmdtesting.Context(c)
+	cmd.SetProgressTerminal(ctx, 0)
+	bar := ctx.NewProgressBar("up", 10)
+	bar.Set(5)
+	bar.Done()
+	c.Assert(cmdtesting.Stderr(ctx), gc.Equals, ""+
+		"up [                              ]   0%\n"+
+		"\x1b[1A\x1b[J"+
+		"up [===============               ]  50%\n"+
+		"\x1b[1A\x1b[J"+
+		"up [==============================] 100%\n",
+	)
+}
+
+func (s *ProgressSuite) TestProgressBarRedrawsOnlyOnChange(c *gc.C) {
+	ctx := cmdtesting.Context(c)
+	cmd.SetProgressTerminal(ctx, 0)
+	bar := ctx.NewProgressBar("up", 1000)
+	for i := 0; i < 10; i++ {
+		bar.Write([]byte("x"))
+	}
+	bar.Add(10)
+	c.Assert(cmdtesting.Stderr(ctx), gc.Equals, ""+
+		"up [                              ]   0%\n"+
+		"\x1b[1A\x1b[J"+
+		"up [                              ]   1%\n"+
+		"\x1b[1A\x1b[J"+
+		"up [                              ]   2%\n",
+	)
+}
+
+func (s *ProgressSuite) TestTruncateToTerminalWidth(c *gc.C) {
+	ctx := cmdtesting.Context(c)
+	cmd.SetProgressTerminal(ctx, 12)
+	l := ctx.NewTaskList()
+	l.Add("a long task name").Start()
+	l.Add("日本語の名前").Start()
+	c.Assert(cmdtesting.Stderr(ctx), gc.Equals, ""+
+		"[ ] a long \n"+
+		"\x1b[1A\x1b[J"+
+		"[-] a long \n"+
+		"\x1b[1A\x1b[J"+
+		"[-] a long \n"+
+		"[ ] 日本語\n"+
+		"\x1b[2A\x1b[J"+
+		"[-] a long \n"+
+		"[-] 日本語\n",
+	)
+}
+
+func (s *ProgressSuite) TestSpinner(c *gc.C) {
+	ctx := cmdtesting.Context(c)
+	spinner := ctx.NewSpinner("waiting")
+	spinner.Update("still waiting")
+	spinner.Done("ready")
+	c.Assert(cmdtesting.Stderr(ctx), gc.Equals, "waiting...\nwaiting: ready\n")
+
+	ctx = cmdtesting.Context(c)
+	cmd.SetProgressTerminal(ctx, 0)
+	spinner = ctx.NewSpinner("waiting")
+	spinner.Update("machine 0")
+	spinner.Done("ready")
+	c.Assert(cmdtesting.Stderr(ctx), gc.Equals, ""+
+		"waiting |\n"+
+		"\x1b[1A\x1b[J"+
+		"waiting / machine 0\n"+
+		"\x1b[1A\x1b[J"+
+		"waiting: ready\n",
+	)
+}
+
+func (s *ProgressSuite) TestTaskList(c *gc.C) {
+	ctx := cmdtesting.Context(c)
+	tasks := ctx.NewTaskList()
+	mysql := tasks.Add("mysql")
+	wordpress := tasks.Add("wordpress")
+	mysql.Start()
+	wordpress.Start()
+	mysql.Done()
+	wordpress.Fail(errors.New("no space"))
+	tasks.Close()
+	c.Assert(cmdtesting.Stderr(ctx), gc.Equals, `
+mysql: started
+wordpress: started
+mysql: done
+wordpress: failed: no space
+`[1:])
+
+	ctx = cmdtesting.Context(c)
+	cmd.SetProgressTerminal(ctx, 0)
+	tasks = ctx.NewTaskList()
+	mysql = tasks.Add("mysql")
+	mysql.Start()
+	mysql.Done()
+	tasks.Close()
+	c.Assert(cmdtesting.Stderr(ctx), gc.Equals, ""+
+		"[ ] mysql\n"+
+		"\x1b[1A\x1b[J"+
+		"[-] mysql\n"+
+		"\x1b[1A\x1b[J"+
+		"[x] mysql\n"+
+		"\x1b[1A\x1b[J"+
+		"[x] mysql\n",
+	)
+}
+
+func (s *ProgressSuite) TestQuiet(c *gc.C) {
+	ctx := cmdtesting.Context(c)
+	log := &cmd.Log{Quiet: true}
+	c.Assert(log.Start(ctx), gc.IsNil)
+	bar := ctx.NewProgressBar("uploading", 10)
+	bar.Set(5)
+	bar.Done()
+	spinner := ctx.NewSpinner("waiting")
+	spinner.Update("status")
+	spinner.Done("done")
+	tasks := ctx.NewTaskList()
+	tasks.Add("task").Start()
+	tasks.Close()
+	c.Assert(cmdtesting.Stderr(ctx), gc.Equals, "")
+}
+
+func (s *ProgressSuite) TestLogLinesAboveProgress(c *gc.C) {
+	ctx := cmdtesting.Context(c)
+	cmd.SetProgressTerminal(ctx, 0)
+	log := &cmd.Log{}
+	c.Assert(log.Start(ctx), gc.IsNil)
+	bar := ctx.NewProgressBar("up", 10)
+	loggo.GetLogger("test").Warningf("careful")
+	bar.Done()
+	c.Assert(cmdtesting.Stderr(ctx), gc.Equals, ""+
+		"up [                              ]   0%\n"+
+		"\x1b[1A\x1b[J"+
+		"WARNING careful\n"+
+		"up [                              ]   0%\n"+
+		"\x1b[1A\x1b[J"+
+		"up [==============================] 100%\n",
+	)
+}
@@ -0,0 +1,282 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENSE file for details.
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/juju/gnuflag"
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+// Prompting holds the global flags that control interactive prompts.
+// Its flags are registered with a SuperCommand by setting
+// SuperCommandParams.Prompting, or by calling AddFlags from the SetFlags
+// method of any other command. The flags are applied to the Context when
+// they are resolved by ResolveFlags, as Main does.
+type Prompting struct {
+	// AssumeYes causes Context.Confirm to answer yes without asking.
+	AssumeYes bool
+
+	// NoPrompt causes the Context prompting methods to fail rather
+	// than wait for input.
+	NoPrompt bool
+
+	// PipedSecrets allows Context.ReadSecret to read a line from
+	// stdin when it is not a terminal, as in "app login < password".
+	PipedSecrets bool
+}
+
+// AddFlags adds appropriate flags to f.
+func (p *Prompting) AddFlags(f *gnuflag.FlagSet) {
+	f.Var(&promptingValue{p, &p.AssumeYes}, "yes", "Answer yes to all confirmation prompts")
+	f.Var(&promptingValue{p, &p.NoPrompt}, "no-prompt", "Fail instead of prompting for input")
+	f.Var(&promptingValue{p, &p.PipedSecrets}, "secrets-from-stdin", "Read secrets from stdin when it is not a terminal")
+}
+
+// promptingValue is the value of a Prompting flag. It applies the flags
+// to the Context when it is resolved.
+type promptingValue struct {
+	prompting *Prompting
+	target    *bool
+}
+
+// Set implements gnuflag.Value.Set.
+func (v *promptingValue) Set(s string) error {
+	b, err := strconv.ParseBool(s)
+	if err != nil {
+		return err
+	}
+	*v.target = b
+	return nil
+}
+
+// String implements gnuflag.Value.String.
+func (v *promptingValue) String() string {
+	if v.target == nil {
+		return "false"
+	}
+	return strconv.FormatBool(*v.target)
+}
+
+// IsBoolFlag implements the optional method that marks gnuflag boolean
+// flags, so that the flag takes no argument.
+func (v *promptingValue) IsBoolFlag() bool {
+	return true
+}
+
+// ResolveFlag implements FlagResolver by calling Start.
+func (v *promptingValue) ResolveFlag(ctx *Context) error {
+	v.prompting.Start(ctx)
+	return nil
+}
+
+// Start applies the flags to ctx.
+func (p *Prompting) Start(ctx *Context) {
+	ctx.assumeYes = p.AssumeYes
+	ctx.noPrompt = p.NoPrompt
+	ctx.pipedSecrets = p.PipedSecrets
+}
+
+// canPrompt returns an error if the user cannot be asked question, because
+// prompting has been disabled or stdin is not a terminal.
+func (ctx *Context) canPrompt(question string) error {
+	if ctx.noPrompt {
+		return fmt.Errorf("cannot prompt for %q: prompting disabled by --no-prompt", question)
+	}
+	if !isTerminal(ctx.Stdin) {
+		return fmt.Errorf("cannot prompt for %q: stdin is not a terminal", question)
+	}
+	return nil
+}
+
+// isTerminal reports whether r is an interactive terminal. Readers other
+// than files are not, unless they have an IsTerminal method that says
+// otherwise, as the readers used by cmdtesting to drive prompts do.
+func isTerminal(r io.Reader) bool {
+	switch r := r.(type) {
+	case *os.File:
+		return terminal.IsTerminal(int(r.Fd()))
+	case interface {
+		IsTerminal() bool
+	}:
+		return r.IsTerminal()
+	}
+	return false
+}
+
+// Prompt writes question to Stderr and returns the line that the user
+// enters on Stdin. If the user enters nothing, defaultValue is returned.
+func (ctx *Context) Prompt(question, defaultValue string) (string, error) {
+	if err := ctx.canPrompt(question); err != nil {
+		return "", err
+	}
+	if defaultValue != "" {
+		fmt.Fprintf(ctx.Stderr, "%s [%s]: ", question, defaultValue)
+	} else {
+		fmt.Fprintf(ctx.Stderr, "%s: ", question)
+	}
+	answer, err := readLine(ctx.Stdin)
+	if err != nil {
+		return "", err
+	}
+	if answer == "" {
+		return defaultValue, nil
+	}
+	return answer, nil
+}
+
+// ReadSecret writes prompt to Stderr and returns the line that the user
+// enters on Stdin, without echoing it if Stdin is a terminal. If Stdin is
+// not a terminal, ReadSecret fails unless Prompting.PipedSecrets was set,
+// in which case it reads a single line without writing the prompt.
+func (ctx *Context) ReadSecret(prompt string) (string, error) {
+	if !isTerminal(ctx.Stdin) {
+		if !ctx.pipedSecrets {
+			return "", fmt.Errorf("cannot prompt for %q: stdin is not a terminal", prompt)
+		}
+		return readLine(ctx.Stdin)
+	}
+	f, isFile := ctx.Stdin.(*os.File)
+	if err := ctx.canPrompt(prompt); err != nil {
+		return "", err
+	}
+	fmt.Fprintf(ctx.Stderr, "%s: ", prompt)
+	if !isFile {
+		return readLine(ctx.Stdin)
+	}
+	secret, err := terminal.ReadPassword(int(f.Fd()))
+	// The user's newline was not echoed either.
+	fmt.Fprintln(ctx.Stderr)
+	if err != nil {
+		return "", err
+	}
+	return string(secret), nil
+}
+
+// Confirm asks the user a yes or no question, returning true if they
+// answer yes. The default answer is no. If --yes was given, Confirm
+// returns true without asking.
+func (ctx *Context) Confirm(question string) (bool, error) {
+	if ctx.assumeYes {
+		return true, nil
+	}
+	if err := ctx.canPrompt(question); err != nil {
+		return false, err
+	}
+	for {
+		fmt.Fprintf(ctx.Stderr, "%s (y/N): ", question)
+		answer, err := readLine(ctx.Stdin)
+		if err != nil {
+			return false, err
+		}
+		switch strings.ToLower(answer) {
+		case "y", "yes":
+			return true, nil
+		case "", "n", "no":
+			return false, nil
+		}
+		fmt.Fprintf(ctx.Stderr, "Please answer y or n.\n")
+	}
+}
+
+// Select asks the user to choose one of options, which may be entered by
+// name or by number. If the user enters nothing, defaultValue is returned;
+// if that is empty, an answer is required.
+func (ctx *Context) Select(question string, options []string, defaultValue string) (string, error) {
+	if err := ctx.canPrompt(question); err != nil {
+		return "", err
+	}
+	ctx.writeOptions(question, options)
+	for {
+		answer, err := ctx.Prompt("Select", defaultValue)
+		if err != nil {
+			return "", err
+		}
+		if choice, ok := findOption(options, answer); ok {
+			return choice, nil
+		}
+		fmt.Fprintf(ctx.Stderr, "Invalid selection %q.\n", answer)
+	}
+}
+
+// MultiSelect asks the user to choose any number of options, entered by
+// name or by number and separated by commas.
+func (ctx *Context) MultiSelect(question string, options []string) ([]string, error) {
+	if err := ctx.canPrompt(question); err != nil {
+		return nil, err
+	}
+	ctx.writeOptions(question, options)
+outer:
+	for {
+		answer, err := ctx.Prompt("Select (comma separated)", "")
+		if err != nil {
+			return nil, err
+		}
+		var choices []string
+		for _, item := range strings.Split(answer, ",") {
+			item = strings.TrimSpace(item)
+			if item == "" {
+				continue
+			}
+			choice, ok := findOption(options, item)
+			if !ok {
+				fmt.Fprintf(ctx.Stderr, "Invalid selection %q.\n", item)
+				continue outer
+			}
+			if !containsString(choices, choice) {
+				choices = append(choices, choice)
+			}
+		}
+		return choices, nil
+	}
+}
+
+// writeOptions writes question followed by a numbered list of options.
+func (ctx *Context) writeOptions(question string, options []string) {
+	fmt.Fprintf(ctx.Stderr, "%s\n", question)
+	for i, option := range options {
+		fmt.Fprintf(ctx.Stderr, "  %d) %s\n", i+1, option)
+	}
+}
+
+// findOption returns the option named or numbered by answer.
+func findOption(options []string, answer string) (string, bool) {
+	for _, option := range options {
+		if option == answer {
+			return option, true
+		}
+	}
+	if n, err := strconv.Atoi(answer); err == nil && n >= 1 && n <= len(options) {
+		return options[n-1], true
+	}
+	return "", false
+}
+
+// readLine reads a line from r, without its line ending. It reads a byte
+// at a time so that nothing beyond the line is consumed.
+func readLine(r io.Reader) (string, error) {
+	var line []byte
+	buf := make([]byte, 1)
+	for {
+		n, err := r.Read(buf)
+		if n > 0 {
+			if buf[0] == '\n' {
+				break
+			}
+			line = append(line, buf[0])
+		}
+		if err == io.EOF && len(line) > 0 {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+	return strings.TrimSuffix(string(line), "\r"), nil
+}
@@ -0,0 +1,336 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENSE file for details.
+
+package cmd_test
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/juju/gnuflag"
+	"github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/cmd"
+	"github.com/juju/cmd/cmdtesting"
+)
+
+type PromptSuite struct {
+	testing.IsolationSuite
+}
+
+var _ = gc.Suite(&PromptSuite{})
+
+func (s *PromptSuite) TestPrompt(c *gc.C) {
+	p := cmdtesting.NewSeqPrompter(c, "»", `
+Name \[bob\]: »alice
+Name \[bob\]: »
+Colour: »blue
+`[1:])
+	ctx := cmdtesting.ContextWithPrompter(c, p)
+	answer, err := ctx.Prompt("Name", "bob")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(answer, gc.Equals, "alice")
+	answer, err = ctx.Prompt("Name", "bob")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(answer, gc.Equals, "bob")
+	answer, err = ctx.Prompt("Colour", "")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(answer, gc.Equals, "blue")
+	p.AssertDone()
+}
+
+func (s *PromptSuite) TestPromptEOF(c *gc.C) {
+	p := cmdtesting.NewSeqPrompter(c, "»", `
+Name: »»
+`[1:])
+	ctx := cmdtesting.ContextWithPrompter(c, p)
+	_, err := ctx.Prompt("Name", "")
+	c.Assert(err, gc.Equals, io.EOF)
+	p.AssertDone()
+}
+
+func (s *PromptSuite) TestConfirm(c *gc.C) {
+	p := cmdtesting.NewSeqPrompter(c, "»", `
+Continue\? \(y/N\): »maybe
+Please answer y or n.
+Continue\? \(y/N\): »Yes
+Continue\? \(y/N\): »
+`[1:])
+	ctx := cmdtesting.ContextWithPrompter(c, p)
+	ok, err := ctx.Confirm("Continue?")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(ok, jc.IsTrue)
+	ok, err = ctx.Confirm("Continue?")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(ok, jc.IsFalse)
+	p.AssertDone()
+}
+
+func (s *PromptSuite) TestSelect(c *gc.C) {
+	p := cmdtesting.NewSeqPrompter(c, "»", `
+Which cloud\?
+  1\) aws
+  2\) azure
+  3\) lxd
+Select \[lxd\]: »4
+Invalid selection "4".
+Select \[lxd\]: »2
+Which cloud\?
+  1\) aws
+  2\) azure
+  3\) lxd
+Select \[lxd\]: »aws
+Which cloud\?
+  1\) aws
+  2\) azure
+  3\) lxd
+Select \[lxd\]: »
+`[1:])
+	ctx := cmdtesting.ContextWithPrompter(c, p)
+	options := []string{"aws", "azure", "lxd"}
+	for _, expected := range []string{"azure", "aws", "lxd"} {
+		choice, err := ctx.Select("Which cloud?", options, "lxd")
+		c.Assert(err, jc.ErrorIsNil)
+		c.Assert(choice, gc.Equals, expected)
+	}
+	p.AssertDone()
+}
+
+func (s *PromptSuite) TestMultiSelect(c *gc.C) {
+	p := cmdtesting.NewSeqPrompter(c, "»", `
+Which series\?
+  1\) xenial
+  2\) bionic
+Select \(comma separated\): »bionic, trusty
+Invalid selection "trusty".
+Select \(comma separated\): »2, 1, bionic
+Which series\?
+  1\) xenial
+  2\) bionic
+Select \(comma separated\): »
+`[1:])
+	ctx := cmdtesting.ContextWithPrompter(c, p)
+	options := []string{"xenial", "bionic"}
+	choices, err := ctx.MultiSelect("Which series?", options)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(choices, jc.DeepEquals, []string{"bionic", "xenial"})
+	choices, err = ctx.MultiSelect("Which series?", options)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(choices, gc.HasLen, 0)
+	p.AssertDone()
+}
+
+func (s *PromptSuite) TestNoPrompt(c *gc.C) {
+	ctx := cmdtesting.Context(c)
+	(&cmd.Prompting{NoPrompt: true}).Start(ctx)
+	_, err := ctx.Prompt("Name", "bob")
+	c.Check(err, gc.ErrorMatches, `cannot prompt for "Name": prompting disabled by --no-prompt`)
+	_, err = ctx.Confirm("Continue?")
+	c.Check(err, gc.ErrorMatches, `cannot prompt for "Continue\?": prompting disabled by --no-prompt`)
+	_, err = ctx.Select("Which?", []string{"a"}, "")
+	c.Check(err, gc.ErrorMatches, `cannot prompt for "Which\?": .*`)
+	_, err = ctx.MultiSelect("Which?", []string{"a"})
+	c.Check(err, gc.ErrorMatches, `cannot prompt for "Which\?": .*`)
+	c.Check(cmdtesting.Stderr(ctx), gc.Equals, "")
+}
+
+func (s *PromptSuite) TestAssumeYes(c *gc.C) {
+	ctx := cmdtesting.Context(c)
+	(&cmd.Prompting{AssumeYes: true, NoPrompt: true}).Start(ctx)
+	ok, err := ctx.Confirm("Continue?")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(ok, jc.IsTrue)
+	c.Assert(cmdtesting.Stderr(ctx), gc.Equals, "")
+}
+
+func (s *PromptSuite) TestNotATerminal(c *gc.C) {
+	path := filepath.Join(c.MkDir(), "input")
+	err := ioutil.WriteFile(path, []byte("y\n"), 0644)
+	c.Assert(err, jc.ErrorIsNil)
+	f, err := os.Open(path)
+	c.Assert(err, jc.ErrorIsNil)
+	defer f.Close()
+	ctx := cmdtesting.Context(c)
+	ctx.Stdin = f
+	_, err = ctx.Confirm("Continue?")
+	c.Assert(err, gc.ErrorMatches, `cannot prompt for "Continue\?": stdin is not a terminal`)
+}
+
+func (s *PromptSuite) TestNotInteractiveReader(c *gc.C) {
+	ctx := cmdtesting.Context(c)
+	ctx.Stdin = strings.NewReader("y\n")
+	_, err := ctx.Confirm("Continue?")
+	c.Assert(err, gc.ErrorMatches, `cannot prompt for "Continue\?": stdin is not a terminal`)
+	c.Assert(cmdtesting.Stderr(ctx), gc.Equals, "")
+}
+
+type confirmCommand struct {
+	cmd.CommandBase
+	prompting *cmd.Prompting
+}
+
+func (c *confirmCommand) SetFlags(f *gnuflag.FlagSet) {
+	if c.prompting != nil {
+		c.prompting.AddFlags(f)
+	}
+}
+
+func (c *confirmCommand) Info() *cmd.Info {
+	return &cmd.Info{Name: "confirm"}
+}
+
+func (c *confirmCommand) Run(ctx *cmd.Context) error {
+	ok, err := ctx.Confirm("Continue?")
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(ctx.Stdout, ok)
+	return nil
+}
+
+func (s *PromptSuite) TestSuperCommandFlags(c *gc.C) {
+	for i, test := range []struct {
+		args   []string
+		code   int
+		stdout string
+		stderr string
+	}{{
+		args:   []string{"confirm", "--yes"},
+		stdout: "true\n",
+	}, {
+		args:   []string{"--no-prompt", "confirm"},
+		code:   1,
+		stderr: `ERROR cannot prompt for "Continue?": prompting disabled by --no-prompt` + "\n",
+	}} {
+		c.Logf("test %d: %q", i, test.args)
+		super := cmd.NewSuperCommand(cmd.SuperCommandParams{
+			Name:      "super",
+			Prompting: &cmd.Prompting{},
+		})
+		super.Register(&confirmCommand{})
+		ctx := cmdtesting.Context(c)
+		code := cmd.Main(super, ctx, test.args)
+		c.Check(code, gc.Equals, test.code)
+		c.Check(cmdtesting.Stdout(ctx), gc.Equals, test.stdout)
+		c.Check(cmdtesting.Stderr(ctx), gc.Equals, test.stderr)
+	}
+}
+
+func (s *PromptSuite) TestCommandFlags(c *gc.C) {
+	for i, test := range []struct {
+		args   []string
+		code   int
+		stdout string
+		stderr string
+	}{{
+		args:   []string{"--yes"},
+		stdout: "true\n",
+	}, {
+		args:   []string{"--no-prompt"},
+		code:   1,
+		stderr: `ERROR cannot prompt for "Continue?": prompting disabled by --no-prompt` + "\n",
+	}} {
+		c.Logf("test %d: %q", i, test.args)
+		ctx := cmdtesting.Context(c)
+		code := cmd.Main(&confirmCommand{prompting: &cmd.Prompting{}}, ctx, test.args)
+		c.Check(code, gc.Equals, test.code)
+		c.Check(cmdtesting.Stdout(ctx), gc.Equals, test.stdout)
+		c.Check(cmdtesting.Stderr(ctx), gc.Equals, test.stderr)
+	}
+}
+
+func (s *PromptSuite) TestReadSecret(c *gc.C) {
+	ctx := cmdtesting.ContextWithSecrets(c, "sekrit", "t0ken")
+	password, err := ctx.ReadSecret("Password")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(password, gc.Equals, "sekrit")
+	token, err := ctx.ReadSecret("Token")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(token, gc.Equals, "t0ken")
+	c.Assert(cmdtesting.Stderr(ctx), gc.Equals, "Password: Token: ")
+	cmdtesting.CheckSecretsNotWritten(c, ctx, "sekrit", "t0ken")
+}
+
+func (s *PromptSuite) TestReadSecretNoPrompt(c *gc.C) {
+	ctx := cmdtesting.ContextWithSecrets(c, "sekrit")
+	(&cmd.Prompting{NoPrompt: true}).Start(ctx)
+	_, err := ctx.ReadSecret("Password")
+	c.Assert(err, gc.ErrorMatches, `cannot prompt for "Password": prompting disabled by --no-prompt`)
+}
+
+func (s *PromptSuite) TestReadSecretPiped(c *gc.C) {
+	path := filepath.Join(c.MkDir(), "input")
+	err := ioutil.WriteFile(path, []byte("sekrit\nmore\n"), 0644)
+	c.Assert(err, jc.ErrorIsNil)
+	f, err := os.Open(path)
+	c.Assert(err, jc.ErrorIsNil)
+	defer f.Close()
+	ctx := cmdtesting.Context(c)
+	ctx.Stdin = f
+	_, err = ctx.ReadSecret("Password")
+	c.Assert(err, gc.ErrorMatches, `cannot prompt for "Password": stdin is not a terminal`)
+
+	// Piped secrets are read even with --no-prompt, as no prompt is needed.
+	(&cmd.Prompting{NoPrompt: true, PipedSecrets: true}).Start(ctx)
+	password, err := ctx.ReadSecret("Password")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(password, gc.Equals, "sekrit")
+	c.Assert(cmdtesting.Stderr(ctx), gc.Equals, "")
+}
+
+type secretCommand struct {
+	cmd.CommandBase
+	prompting cmd.Prompting
+}
+
+func (c *secretCommand) Info() *cmd.Info {
+	return &cmd.Info{Name: "secret"}
+}
+
+func (c *secretCommand) SetFlags(f *gnuflag.FlagSet) {
+	c.prompting.AddFlags(f)
+}
+
+func (c *secretCommand) Run(ctx *cmd.Context) error {
+	secret, err := ctx.ReadSecret("Password")
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(ctx.Stdout, len(secret))
+	return nil
+}
+
+func (s *PromptSuite) TestSecretsFromStdinFlag(c *gc.C) {
+	path := filepath.Join(c.MkDir(), "input")
+	err := ioutil.WriteFile(path, []byte("sekrit\n"), 0644)
+	c.Assert(err, jc.ErrorIsNil)
+	for i, test := range []struct {
+		args   []string
+		code   int
+		stdout string
+		stderr string
+	}{{
+		code:   1,
+		stderr: `ERROR cannot prompt for "Password": stdin is not a terminal` + "\n",
+	}, {
+		args:   []string{"--secrets-from-stdin"},
+		stdout: "6\n",
+	}} {
+		c.Logf("test %d: %q", i, test.args)
+		f, err := os.Open(path)
+		c.Assert(err, jc.ErrorIsNil)
+		ctx := cmdtesting.Context(c)
+		ctx.Stdin = f
+		code := cmd.Main(&secretCommand{}, ctx, test.args)
+		f.Close()
+		c.Check(code, gc.Equals, test.code)
+		c.Check(cmdtesting.Stdout(ctx), gc.Equals, test.stdout)
+		c.Check(cmdtesting.Stderr(ctx), gc.Equals, test.stderr)
+	}
+}
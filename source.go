@@ -0,0 +1,135 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENSE file for details.
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/juju/utils"
+)
+
+// SourcedStringValue implements gnuflag.Value for string flags whose value
+// may be given indirectly, keeping secrets such as passwords out of shell
+// history and process listings. The flag accepts:
+//
+//	@path     the contents of the file, relative to the command's Dir;
+//	env:NAME  the value of the environment variable NAME;
+//	-         the contents of stdin;
+//	@@text    the literal string "@text".
+//
+// Any other argument is used as given. A single trailing newline is removed
+// from file and stdin contents. Because the value can only be read once the
+// command's Context is known, Target is not set until ResolveFlag is
+// called, which cmd.Main does after Init and before Run.
+type SourcedStringValue struct {
+	// Target receives the resolved value.
+	Target *string
+
+	raw      string
+	resolved bool
+}
+
+var _ FlagResolver = (*SourcedStringValue)(nil)
+
+// NewSourcedStringValue returns a SourcedStringValue that stores its
+// resolved value in target. The default value is resolved in the same way
+// as a value given on the command line, so it may itself refer to a file or
+// environment variable.
+func NewSourcedStringValue(defaultValue string, target *string) *SourcedStringValue {
+	return &SourcedStringValue{
+		Target: target,
+		raw:    defaultValue,
+	}
+}
+
+// Set implements gnuflag.Value's Set method.
+func (v *SourcedStringValue) Set(s string) error {
+	switch {
+	case s == "@":
+		return errors.New("expected file name after @")
+	case s == "env:":
+		return errors.New("expected environment variable name after env:")
+	}
+	v.raw = s
+	v.resolved = false
+	return nil
+}
+
+// String implements gnuflag.Value's String method. It returns the argument
+// as given, never the resolved value.
+func (v *SourcedStringValue) String() string {
+	return v.raw
+}
+
+// IsStdin reports whether the value is read from stdin.
+func (v *SourcedStringValue) IsStdin() bool {
+	return v.raw == "-"
+}
+
+// ResolveFlag implements FlagResolver by reading the value from its source
+// and storing it in Target.
+func (v *SourcedStringValue) ResolveFlag(ctx *Context) error {
+	if v.resolved {
+		return nil
+	}
+	value, err := v.read(ctx)
+	if err != nil {
+		return err
+	}
+	*v.Target = value
+	v.resolved = true
+	return nil
+}
+
+func (v *SourcedStringValue) read(ctx *Context) (string, error) {
+	switch {
+	case v.raw == "-":
+		data, err := ioutil.ReadAll(ctx.Stdin)
+		if err != nil {
+			return "", err
+		}
+		return trimNewline(string(data)), nil
+	case strings.HasPrefix(v.raw, "@@"):
+		return v.raw[1:], nil
+	case strings.HasPrefix(v.raw, "@"):
+		path, err := utils.NormalizePath(v.raw[1:])
+		if err != nil {
+			return "", err
+		}
+		data, err := ioutil.ReadFile(ctx.AbsPath(path))
+		if err != nil {
+			return "", err
+		}
+		return trimNewline(string(data)), nil
+	case strings.HasPrefix(v.raw, "env:"):
+		name := v.raw[len("env:"):]
+		value, ok := ctx.lookupEnv(name)
+		if !ok {
+			return "", fmt.Errorf("environment variable %q not set", name)
+		}
+		return value, nil
+	}
+	return v.raw, nil
+}
+
+// lookupEnv returns the value of an environment variable in the context.
+// A Context without an environment of its own, such as that from
+// DefaultContext, uses the process environment.
+func (ctx *Context) lookupEnv(key string) (string, bool) {
+	if ctx.Env == nil {
+		return os.LookupEnv(key)
+	}
+	value, ok := ctx.Env[key]
+	return value, ok
+}
+
+// trimNewline removes a single trailing newline from s.
+func trimNewline(s string) string {
+	s = strings.TrimSuffix(s, "\n")
+	return strings.TrimSuffix(s, "\r")
+}
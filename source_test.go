@@ -0,0 +1,173 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENSE file for details.
+
+package cmd_test
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/juju/gnuflag"
+	"github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/cmd"
+	"github.com/juju/cmd/cmdtesting"
+)
+
+type SourcedStringSuite struct {
+	testing.IsolationSuite
+}
+
+var _ = gc.Suite(&SourcedStringSuite{})
+
+func (s *SourcedStringSuite) resolve(c *gc.C, ctx *cmd.Context, args ...string) (string, error) {
+	var password string
+	f := cmdtesting.NewFlagSet()
+	value := cmd.NewSourcedStringValue("", &password)
+	f.Var(value, "password", "the password")
+	f.Var(value, "p", "the password")
+	if err := f.Parse(true, args); err != nil {
+		return "", err
+	}
+	err := cmd.ResolveFlags(ctx, f)
+	return password, err
+}
+
+func (s *SourcedStringSuite) TestSources(c *gc.C) {
+	dir := c.MkDir()
+	err := ioutil.WriteFile(filepath.Join(dir, "secret"), []byte("from file\n"), 0600)
+	c.Assert(err, jc.ErrorIsNil)
+	for i, test := range []struct {
+		arg      string
+		stdin    string
+		expected string
+	}{{
+		arg:      "literal",
+		expected: "literal",
+	}, {
+		arg:      "@secret",
+		expected: "from file",
+	}, {
+		arg:      "@" + filepath.Join(dir, "secret"),
+		expected: "from file",
+	}, {
+		arg:      "@@literal",
+		expected: "@literal",
+	}, {
+		arg:      "env:SECRET",
+		expected: "from env",
+	}, {
+		arg:      "-",
+		stdin:    "from stdin\r\n",
+		expected: "from stdin",
+	}} {
+		c.Logf("test %d: %q", i, test.arg)
+		ctx := cmdtesting.ContextForDir(c, dir)
+		ctx.Env = map[string]string{"SECRET": "from env"}
+		ctx.Stdin = bytes.NewBufferString(test.stdin)
+		password, err := s.resolve(c, ctx, "--password", test.arg)
+		c.Check(err, jc.ErrorIsNil)
+		c.Check(password, gc.Equals, test.expected)
+	}
+}
+
+func (s *SourcedStringSuite) TestErrors(c *gc.C) {
+	ctx := cmdtesting.Context(c)
+	ctx.Env = map[string]string{}
+	_, err := s.resolve(c, ctx, "--password", "@missing")
+	c.Check(err, gc.ErrorMatches, `invalid value "@missing" for flag -p: open .*missing: no such file or directory`)
+	_, err = s.resolve(c, ctx, "-p", "env:MISSING")
+	c.Check(err, gc.ErrorMatches, `invalid value "env:MISSING" for flag -p: environment variable "MISSING" not set`)
+	_, err = s.resolve(c, ctx, "--password", "@")
+	c.Check(err, gc.ErrorMatches, `invalid value "@" for flag --password: expected file name after @`)
+}
+
+func (s *SourcedStringSuite) TestProcessEnvironment(c *gc.C) {
+	s.PatchEnvironment("TEST_SECRET", "from process")
+	ctx := cmdtesting.Context(c)
+	password, err := s.resolve(c, ctx, "--password", "env:TEST_SECRET")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(password, gc.Equals, "from process")
+}
+
+func (s *SourcedStringSuite) TestDefault(c *gc.C) {
+	var password string
+	f := cmdtesting.NewFlagSet()
+	f.Var(cmd.NewSourcedStringValue("env:SECRET", &password), "password", "")
+	err := f.Parse(true, nil)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(password, gc.Equals, "")
+
+	ctx := cmdtesting.Context(c)
+	ctx.Env = map[string]string{"SECRET": "from env"}
+	err = cmd.ResolveFlags(ctx, f)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(password, gc.Equals, "from env")
+	c.Assert(f.Lookup("password").Value.String(), gc.Equals, "env:SECRET")
+}
+
+func (s *SourcedStringSuite) TestOneStdinFlag(c *gc.C) {
+	var password string
+	var file cmd.FileVar
+	file.SetStdin()
+	f := cmdtesting.NewFlagSet()
+	f.Var(cmd.NewSourcedStringValue("", &password), "password", "")
+	f.Var(&file, "file", "")
+	err := f.Parse(true, []string{"--password", "-", "--file", "-"})
+	c.Assert(err, jc.ErrorIsNil)
+	err = cmd.ResolveFlags(cmdtesting.Context(c), f)
+	c.Assert(err, gc.ErrorMatches, "--file and --password cannot both read from stdin")
+}
+
+type sourcedCommand struct {
+	cmd.CommandBase
+	password string
+	cert     string
+}
+
+func (c *sourcedCommand) Info() *cmd.Info {
+	return &cmd.Info{Name: "sourced"}
+}
+
+func (c *sourcedCommand) SetFlags(f *gnuflag.FlagSet) {
+	f.Var(cmd.NewSourcedStringValue("", &c.password), "password", "")
+	f.Var(cmd.NewSourcedStringValue("", &c.cert), "cert", "")
+}
+
+func (c *sourcedCommand) Run(ctx *cmd.Context) error {
+	fmt.Fprintf(ctx.Stdout, "%s %s\n", c.password, c.cert)
+	return nil
+}
+
+func (s *SourcedStringSuite) TestMain(c *gc.C) {
+	ctx := cmdtesting.Context(c)
+	ctx.Stdin = bytes.NewBufferString("sekrit\n")
+	code := cmd.Main(&sourcedCommand{}, ctx, []string{"--password", "-", "--cert", "@@cert"})
+	c.Assert(code, gc.Equals, 0)
+	c.Assert(cmdtesting.Stdout(ctx), gc.Equals, "sekrit @cert\n")
+
+	ctx = cmdtesting.Context(c)
+	code = cmd.Main(&sourcedCommand{}, ctx, []string{"--password", "-", "--cert", "-"})
+	c.Assert(code, gc.Equals, 2)
+	c.Assert(cmdtesting.Stderr(ctx), gc.Equals, "ERROR --cert and --password cannot both read from stdin\n")
+}
+
+func (s *SourcedStringSuite) TestSuperCommand(c *gc.C) {
+	super := cmd.NewSuperCommand(cmd.SuperCommandParams{Name: "super"})
+	super.Register(&sourcedCommand{})
+	ctx := cmdtesting.Context(c)
+	ctx.Env = map[string]string{"PASSWORD": "sekrit"}
+	code := cmd.Main(super, ctx, []string{"sourced", "--password", "env:PASSWORD"})
+	c.Assert(code, gc.Equals, 0)
+	c.Assert(cmdtesting.Stdout(ctx), gc.Equals, "sekrit \n")
+}
+
+func (s *SourcedStringSuite) TestRunCommand(c *gc.C) {
+	ctx, err := cmdtesting.RunCommand(c, &sourcedCommand{}, "--password", "@@x")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(cmdtesting.Stdout(ctx), gc.Equals, "@x \n")
+}
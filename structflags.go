@@ -0,0 +1,314 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENSE file for details.
+
+package cmd
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/juju/gnuflag"
+)
+
+var (
+	stringType    = reflect.TypeOf("")
+	stringsType   = reflect.TypeOf([]string(nil))
+	stringMapType = reflect.TypeOf(map[string]string(nil))
+	flagValueType = reflect.TypeOf((*gnuflag.Value)(nil)).Elem()
+)
+
+// BindFlags adds a flag to f for every tagged field of the struct pointed to
+// by target. This allows a command to describe its options declaratively
+// rather than registering each one by hand in SetFlags:
+//
+//	type deployOptions struct {
+//		Model  string        `flag:"model,m" help:"Model to operate in" env:"JUJU_MODEL"`
+//		Series []string      `flag:"series" default:"xenial,bionic" help:"Series to deploy to"`
+//		Config cmd.FileVar   `flag:"config" stdin:"-" help:"Path to a YAML config file"`
+//		Wait   time.Duration `flag:"wait" default:"5m" help:"How long to wait"`
+//	}
+//
+//	func (c *deployCommand) SetFlags(f *gnuflag.FlagSet) {
+//		cmd.BindFlags(f, &c.options)
+//	}
+//
+// The following struct tags are recognised:
+//
+//	flag     a comma separated list of names for the flag; the first
+//	         name is the primary one. A value of "-" skips the field.
+//	help     the usage text shown in the help output.
+//	default  the default value, parsed as if given on the command line.
+//	         A value given on the command line replaces the default,
+//	         even for fields such as AppendStringsValue.
+//	env      an environment variable which, when set in the Context,
+//	         overrides the default value. It is read when the flags are
+//	         resolved by ResolveFlags, after Init and before Run.
+//	stdin    for FileVar fields, a comma separated list of stdin markers.
+//	choices  for string fields, a comma separated list of allowed values;
+//	         the flag is bound to an EnumValue.
+//	negatable
+//	         for bool fields, also registers --no-<name> flags, as
+//	         NegatableBoolVar does.
+//
+// Supported field types are string, bool, int, int64, uint64, float64,
+// time.Duration (as a DurationValue), time.Time (as a TimeValue), []string
+// (as a StringsValue), AppendStringsValue, map[string]string (as a
+// StringMap), and any type whose pointer implements gnuflag.Value, such as
+// FileVar. Untagged embedded structs are traversed.
+//
+// BindFlags panics if target is not a pointer to a struct, or if a tag is
+// invalid, as these are programming errors.
+func BindFlags(f *gnuflag.FlagSet, target interface{}) {
+	v := reflect.ValueOf(target)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		panic(fmt.Sprintf("BindFlags: expected pointer to struct, got %T", target))
+	}
+	bindStructFlags(f, v.Elem())
+}
+
+func bindStructFlags(f *gnuflag.FlagSet, v reflect.Value) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag, ok := field.Tag.Lookup("flag")
+		if !ok {
+			if field.Anonymous && field.Type.Kind() == reflect.Struct {
+				bindStructFlags(f, v.Field(i))
+			}
+			continue
+		}
+		if tag == "-" {
+			continue
+		}
+		if field.PkgPath != "" {
+			panic(fmt.Sprintf("BindFlags: field %s is not exported", field.Name))
+		}
+		names := strings.Split(tag, ",")
+		for _, name := range names {
+			if name == "" {
+				panic(fmt.Sprintf("BindFlags: field %s has an empty flag name", field.Name))
+			}
+		}
+		defaultValue := field.Tag.Get("default")
+		hasDefault := defaultValue != ""
+		if hasDefault {
+			// Assign the default before the flag value is made, as
+			// some values take their default from the field.
+			scratch := reflect.New(field.Type)
+			if err := flagValueFor(field, scratch.Elem()).Set(defaultValue); err != nil {
+				panic(fmt.Sprintf("BindFlags: invalid default %q for field %s: %v", defaultValue, field.Name, err))
+			}
+			v.Field(i).Set(scratch.Elem())
+		}
+		value := flagValueFor(field, v.Field(i))
+		usage := field.Tag.Get("help")
+		if enum, ok := value.(*EnumValue); ok {
+			usage = enum.Doc(usage)
+		}
+		env := field.Tag.Get("env")
+		if env != "" {
+			usage = strings.TrimSpace(usage + " ($" + env + ")")
+		}
+		if hasDefault || env != "" {
+			value = &boundValue{Value: value, field: v.Field(i), env: env}
+		}
+		for _, name := range names {
+			f.Var(value, name, usage)
+		}
+		if _, ok := field.Tag.Lookup("negatable"); ok {
+			p, ok := v.Field(i).Addr().Interface().(*bool)
+			if !ok {
+				panic(fmt.Sprintf("BindFlags: negatable given for non-bool field %s", field.Name))
+			}
+			var negated gnuflag.Value = (*negatedBoolValue)(p)
+			if bound, ok := value.(*boundValue); ok {
+				negated = &boundNegation{Value: negated, bound: bound}
+			}
+			for _, name := range names {
+				if len(name) > 1 {
+					f.Var(negated, "no-"+name, usage)
+				}
+			}
+		}
+	}
+}
+
+// boundValue wraps the value of a flag bound by BindFlags that has a
+// default or an environment variable. The first value given on the
+// command line replaces the default rather than adding to it, and if no
+// value is given the environment variable is read when the flag is
+// resolved.
+type boundValue struct {
+	gnuflag.Value
+	field reflect.Value
+	env   string
+	set   bool
+}
+
+// Set implements gnuflag.Value.
+func (v *boundValue) Set(s string) error {
+	if !v.set {
+		v.set = true
+		v.clear()
+	}
+	return v.Value.Set(s)
+}
+
+// clear empties slice and map fields, such as AppendStringsValue and
+// StringMap fields, which add each value to what they already hold.
+func (v *boundValue) clear() {
+	switch v.field.Kind() {
+	case reflect.Slice, reflect.Map:
+		v.field.Set(reflect.Zero(v.field.Type()))
+	}
+}
+
+// IsBoolFlag tells gnuflag whether the wrapped flag takes an argument.
+func (v *boundValue) IsBoolFlag() bool {
+	b, ok := v.Value.(interface {
+		IsBoolFlag() bool
+	})
+	return ok && b.IsBoolFlag()
+}
+
+// ResolveFlag implements FlagResolver by setting the flag from its
+// environment variable in ctx, if it was not given on the command line.
+func (v *boundValue) ResolveFlag(ctx *Context) error {
+	if v.set || v.env == "" {
+		return nil
+	}
+	value, _ := ctx.lookupEnv(v.env)
+	if value == "" {
+		return nil
+	}
+	if err := v.Set(value); err != nil {
+		return fmt.Errorf("cannot use $%s=%q: %v", v.env, value, err)
+	}
+	return nil
+}
+
+func (v *boundValue) unwrap() gnuflag.Value {
+	return v.Value
+}
+
+// boundNegation wraps the --no-<name> twin of a flag bound by BindFlags
+// that has a default or an environment variable. Giving it counts as
+// giving the flag, so that the environment variable does not override it.
+type boundNegation struct {
+	gnuflag.Value
+	bound *boundValue
+}
+
+// Set implements gnuflag.Value.
+func (v *boundNegation) Set(s string) error {
+	v.bound.set = true
+	return v.Value.Set(s)
+}
+
+// IsBoolFlag tells gnuflag that the flag does not take an argument.
+func (v *boundNegation) IsBoolFlag() bool {
+	return true
+}
+
+func (v *boundNegation) unwrap() gnuflag.Value {
+	return v.Value
+}
+
+// flagValueFor returns the gnuflag.Value used to set the given field.
+func flagValueFor(field reflect.StructField, v reflect.Value) gnuflag.Value {
+	ptr := v.Addr()
+	if ptr.Type().Implements(flagValueType) {
+		value := ptr.Interface().(gnuflag.Value)
+		if fileVar, ok := value.(*FileVar); ok {
+			if markers, ok := field.Tag.Lookup("stdin"); ok {
+				fileVar.SetStdin(strings.Split(markers, ",")...)
+			}
+		}
+		return value
+	}
+	if choices, ok := field.Tag.Lookup("choices"); ok {
+		if field.Type != stringType {
+			panic(fmt.Sprintf("BindFlags: choices given for non-string field %s", field.Name))
+		}
+		target := ptr.Interface().(*string)
+		return NewEnumValue(*target, target, strings.Split(choices, ","))
+	}
+	switch p := ptr.Interface().(type) {
+	case *time.Duration:
+		return NewDurationValue(*p, p)
+	case *time.Time:
+		return NewTimeValue(*p, p)
+	}
+	switch field.Type {
+	case stringsType:
+		return NewStringsValue(*ptr.Interface().(*[]string), ptr.Interface().(*[]string))
+	case stringMapType:
+		return StringMap{Mapping: ptr.Interface().(*map[string]string)}
+	}
+	// For the basic types, borrow gnuflag's own values so that
+	// they are rendered in the same way as any other flag.
+	scratch := gnuflag.NewFlagSet("", gnuflag.ContinueOnError)
+	switch p := ptr.Interface().(type) {
+	case *string:
+		scratch.StringVar(p, "x", *p, "")
+	case *bool:
+		scratch.BoolVar(p, "x", *p, "")
+	case *int:
+		scratch.IntVar(p, "x", *p, "")
+	case *int64:
+		scratch.Int64Var(p, "x", *p, "")
+	case *uint64:
+		scratch.Uint64Var(p, "x", *p, "")
+	case *float64:
+		scratch.Float64Var(p, "x", *p, "")
+	default:
+		panic(fmt.Sprintf("BindFlags: unsupported type %s for field %s", field.Type, field.Name))
+	}
+	return scratch.Lookup("x").Value
+}
+
+// BindArgs assigns the positional arguments in args to the fields of the
+// struct pointed to by target that carry an "arg" tag, in field order. The
+// tag holds the name of the argument used in error messages, optionally
+// followed by ",optional". A trailing []string field receives all of the
+// remaining arguments. It is intended to be called from a command's Init.
+func BindArgs(args []string, target interface{}) error {
+	v := reflect.ValueOf(target)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		panic(fmt.Sprintf("BindArgs: expected pointer to struct, got %T", target))
+	}
+	v = v.Elem()
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag, ok := field.Tag.Lookup("arg")
+		if !ok {
+			continue
+		}
+		parts := strings.Split(tag, ",")
+		name := parts[0]
+		optional := len(parts) > 1 && parts[1] == "optional"
+		switch field.Type {
+		case stringsType:
+			if len(args) == 0 && !optional {
+				return fmt.Errorf("no %s specified", name)
+			}
+			v.Field(i).Set(reflect.ValueOf(args))
+			args = nil
+		case stringType:
+			if len(args) == 0 {
+				if optional {
+					continue
+				}
+				return fmt.Errorf("no %s specified", name)
+			}
+			v.Field(i).SetString(args[0])
+			args = args[1:]
+		default:
+			panic(fmt.Sprintf("BindArgs: unsupported type %s for field %s", field.Type, field.Name))
+		}
+	}
+	return CheckEmpty(args)
+}
@@ -0,0 +1,300 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENSE file for details.
+
+package cmd_test
+
+import (
+	"time"
+
+	"github.com/juju/gnuflag"
+	"github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/cmd"
+	"github.com/juju/cmd/cmdtesting"
+)
+
+type StructFlagsSuite struct {
+	testing.IsolationSuite
+}
+
+var _ = gc.Suite(&StructFlagsSuite{})
+
+type commonOptions struct {
+	Model string `flag:"model,m" help:"Model to operate in" env:"TEST_MODEL"`
+}
+
+type bindOptions struct {
+	commonOptions
+	Name     string                 `flag:"name" default:"foo" help:"A name"`
+	Force    bool                   `flag:"force,f" help:"Do it anyway"`
+	Count    int                    `flag:"count" default:"3" help:"How many"`
+	Size     int64                  `flag:"size" help:"How big"`
+	Limit    uint64                 `flag:"limit" help:"How far"`
+	Ratio    float64                `flag:"ratio" default:"0.5" help:"How much"`
+	Wait     time.Duration          `flag:"wait" default:"5m" help:"How long"`
+	Series   []string               `flag:"series" default:"xenial,bionic" help:"Which series"`
+	Tags     cmd.AppendStringsValue `flag:"tag" help:"Add a tag"`
+	Config   map[string]string      `flag:"config" help:"Set config"`
+	File     cmd.FileVar            `flag:"file" stdin:"-" help:"Path to a file"`
+	Format   string                 `flag:"format" choices:"yaml,json" default:"yaml" help:"Output format"`
+	Ignored  string                 `flag:"-"`
+	Untagged string
+}
+
+func (s *StructFlagsSuite) bind(c *gc.C, opts *bindOptions) *gnuflag.FlagSet {
+	f := cmdtesting.NewFlagSet()
+	cmd.BindFlags(f, opts)
+	return f
+}
+
+func (s *StructFlagsSuite) TestDefaults(c *gc.C) {
+	var opts bindOptions
+	f := s.bind(c, &opts)
+	err := f.Parse(true, nil)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(opts.Model, gc.Equals, "")
+	c.Check(opts.Name, gc.Equals, "foo")
+	c.Check(opts.Force, jc.IsFalse)
+	c.Check(opts.Count, gc.Equals, 3)
+	c.Check(opts.Ratio, gc.Equals, 0.5)
+	c.Check(opts.Wait, gc.Equals, 5*time.Minute)
+	c.Check(opts.Series, jc.DeepEquals, []string{"xenial", "bionic"})
+	c.Check(opts.File.StdinMarkers, jc.DeepEquals, []string{"-"})
+	c.Check(opts.Format, gc.Equals, "yaml")
+	c.Check(f.Lookup("format").Usage, gc.Equals, "Output format (yaml|json)")
+	c.Check(f.Lookup("Ignored"), gc.IsNil)
+	c.Check(f.Lookup("Untagged"), gc.IsNil)
+}
+
+func (s *StructFlagsSuite) TestParse(c *gc.C) {
+	var opts bindOptions
+	f := s.bind(c, &opts)
+	err := f.Parse(true, []string{
+		"-m", "mymodel",
+		"--name", "bar",
+		"-f",
+		"--count=7",
+		"--size", "-2",
+		"--limit", "12",
+		"--ratio", "1.5",
+		"--wait", "10s",
+		"--series", "trusty",
+		"--tag", "a", "--tag", "b",
+		"--config", "x=1", "--config", "y=2",
+		"--file", "-",
+		"--format", "json",
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(opts, jc.DeepEquals, bindOptions{
+		commonOptions: commonOptions{Model: "mymodel"},
+		Name:          "bar",
+		Force:         true,
+		Count:         7,
+		Size:          -2,
+		Limit:         12,
+		Ratio:         1.5,
+		Wait:          10 * time.Second,
+		Series:        []string{"trusty"},
+		Tags:          cmd.AppendStringsValue{"a", "b"},
+		Config:        map[string]string{"x": "1", "y": "2"},
+		File:          cmd.FileVar{Path: "-", StdinMarkers: []string{"-"}},
+		Format:        "json",
+	})
+}
+
+func (s *StructFlagsSuite) TestEnvironmentOverridesDefault(c *gc.C) {
+	var opts bindOptions
+	f := s.bind(c, &opts)
+	err := f.Parse(true, nil)
+	c.Assert(err, jc.ErrorIsNil)
+	ctx := cmdtesting.Context(c)
+	ctx.Env = map[string]string{"TEST_MODEL": "envmodel"}
+	err = cmd.ResolveFlags(ctx, f)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(opts.Model, gc.Equals, "envmodel")
+}
+
+func (s *StructFlagsSuite) TestFlagOverridesEnvironment(c *gc.C) {
+	var opts bindOptions
+	f := s.bind(c, &opts)
+	err := f.Parse(true, []string{"--model", "flagmodel"})
+	c.Assert(err, jc.ErrorIsNil)
+	ctx := cmdtesting.Context(c)
+	ctx.Env = map[string]string{"TEST_MODEL": "envmodel"}
+	err = cmd.ResolveFlags(ctx, f)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(opts.Model, gc.Equals, "flagmodel")
+}
+
+func (s *StructFlagsSuite) TestNegationOverridesEnvironment(c *gc.C) {
+	var opts struct {
+		Wait bool `flag:"wait" negatable:"true" env:"TEST_WAIT" help:"Wait for completion"`
+	}
+	f := cmdtesting.NewFlagSet()
+	cmd.BindFlags(f, &opts)
+	err := f.Parse(true, []string{"--no-wait"})
+	c.Assert(err, jc.ErrorIsNil)
+	ctx := cmdtesting.Context(c)
+	ctx.Env = map[string]string{"TEST_WAIT": "true"}
+	err = cmd.ResolveFlags(ctx, f)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(opts.Wait, jc.IsFalse)
+
+	info := &cmd.Info{Name: "test"}
+	c.Assert(string(info.Help(f)), gc.Equals, `
+Usage: test [flags]
+
+Flags:
+--[no-]wait (= false)
+    Wait for completion ($TEST_WAIT)
+`[1:])
+}
+
+func (s *StructFlagsSuite) TestInvalidEnvironment(c *gc.C) {
+	var opts struct {
+		Count int `flag:"count" default:"3" env:"TEST_COUNT"`
+	}
+	f := cmdtesting.NewFlagSet()
+	cmd.BindFlags(f, &opts)
+	ctx := cmdtesting.Context(c)
+	ctx.Env = map[string]string{"TEST_COUNT": "many"}
+	err := cmd.ResolveFlags(ctx, f)
+	c.Assert(err, gc.ErrorMatches, `invalid value for flag --count: cannot use \$TEST_COUNT="many": .*`)
+}
+
+func (s *StructFlagsSuite) TestValuesReplaceDefault(c *gc.C) {
+	var opts struct {
+		Tags   cmd.AppendStringsValue `flag:"tag" default:"a" env:"TEST_TAGS"`
+		Config map[string]string      `flag:"config" default:"x=1"`
+	}
+	f := cmdtesting.NewFlagSet()
+	cmd.BindFlags(f, &opts)
+	c.Assert(opts.Tags, jc.DeepEquals, cmd.AppendStringsValue{"a"})
+	c.Assert(opts.Config, jc.DeepEquals, map[string]string{"x": "1"})
+
+	err := f.Parse(true, []string{"--tag", "b", "--tag", "c", "--config", "x=2"})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(opts.Tags, jc.DeepEquals, cmd.AppendStringsValue{"b", "c"})
+	c.Check(opts.Config, jc.DeepEquals, map[string]string{"x": "2"})
+
+	ctx := cmdtesting.Context(c)
+	ctx.Env = map[string]string{"TEST_TAGS": "d"}
+	err = cmd.ResolveFlags(ctx, f)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(opts.Tags, jc.DeepEquals, cmd.AppendStringsValue{"b", "c"})
+}
+
+func (s *StructFlagsSuite) TestEnvironmentReplacesDefault(c *gc.C) {
+	var opts struct {
+		Tags cmd.AppendStringsValue `flag:"tag" default:"a" env:"TEST_TAGS"`
+	}
+	f := cmdtesting.NewFlagSet()
+	cmd.BindFlags(f, &opts)
+	ctx := cmdtesting.Context(c)
+	ctx.Env = map[string]string{"TEST_TAGS": "d"}
+	err := cmd.ResolveFlags(ctx, f)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(opts.Tags, jc.DeepEquals, cmd.AppendStringsValue{"d"})
+}
+
+func (s *StructFlagsSuite) TestHelp(c *gc.C) {
+	var opts struct {
+		Model string `flag:"model,m" help:"Model to operate in" env:"TEST_MODEL"`
+		Force bool   `flag:"force" help:"Do it anyway"`
+	}
+	f := cmdtesting.NewFlagSet()
+	cmd.BindFlags(f, &opts)
+	info := &cmd.Info{Name: "test"}
+	c.Assert(string(info.Help(f)), gc.Equals, `
+Usage: test [flags]
+
+Flags:
+--force (= false)
+    Do it anyway
+-m, --model (= "")
+    Model to operate in ($TEST_MODEL)
+`[1:])
+}
+
+func (s *StructFlagsSuite) TestInvalidValue(c *gc.C) {
+	var opts bindOptions
+	f := s.bind(c, &opts)
+	err := f.Parse(true, []string{"--count", "many"})
+	c.Assert(err, gc.ErrorMatches, `invalid value "many" for flag --count: .*`)
+}
+
+func (s *StructFlagsSuite) TestInvalidChoice(c *gc.C) {
+	var opts bindOptions
+	f := s.bind(c, &opts)
+	err := f.Parse(true, []string{"--format", "xml"})
+	c.Assert(err, gc.ErrorMatches, `invalid value "xml" for flag --format: expected one of: yaml, json`)
+}
+
+func (s *StructFlagsSuite) TestBadTarget(c *gc.C) {
+	f := cmdtesting.NewFlagSet()
+	c.Assert(func() { cmd.BindFlags(f, bindOptions{}) }, gc.PanicMatches,
+		`BindFlags: expected pointer to struct, got cmd_test.bindOptions`)
+}
+
+func (s *StructFlagsSuite) TestBadDefault(c *gc.C) {
+	var opts struct {
+		Count int `flag:"count" default:"many"`
+	}
+	f := cmdtesting.NewFlagSet()
+	c.Assert(func() { cmd.BindFlags(f, &opts) }, gc.PanicMatches,
+		`BindFlags: invalid default "many" for field Count: .*`)
+}
+
+func (s *StructFlagsSuite) TestUnsupportedType(c *gc.C) {
+	var opts struct {
+		Ch chan int `flag:"ch"`
+	}
+	f := cmdtesting.NewFlagSet()
+	c.Assert(func() { cmd.BindFlags(f, &opts) }, gc.PanicMatches,
+		`BindFlags: unsupported type chan int for field Ch`)
+}
+
+type bindArgs struct {
+	Application string   `arg:"application name"`
+	Unit        string   `arg:"unit,optional"`
+	Rest        []string `arg:"extra,optional"`
+}
+
+func (s *StructFlagsSuite) TestBindArgs(c *gc.C) {
+	for i, test := range []struct {
+		args     []string
+		expected bindArgs
+		err      string
+	}{{
+		err: "no application name specified",
+	}, {
+		args:     []string{"mysql"},
+		expected: bindArgs{Application: "mysql"},
+	}, {
+		args:     []string{"mysql", "mysql/0"},
+		expected: bindArgs{Application: "mysql", Unit: "mysql/0"},
+	}, {
+		args:     []string{"mysql", "mysql/0", "a", "b"},
+		expected: bindArgs{Application: "mysql", Unit: "mysql/0", Rest: []string{"a", "b"}},
+	}} {
+		c.Logf("test %d: %q", i, test.args)
+		var args bindArgs
+		err := cmd.BindArgs(test.args, &args)
+		if test.err != "" {
+			c.Check(err, gc.ErrorMatches, test.err)
+			continue
+		}
+		c.Check(err, jc.ErrorIsNil)
+		c.Check(args, jc.DeepEquals, test.expected)
+	}
+}
+
+func (s *StructFlagsSuite) TestBindArgsExtra(c *gc.C) {
+	var args struct {
+		Name string `arg:"name"`
+	}
+	err := cmd.BindArgs([]string{"a", "b"}, &args)
+	c.Assert(err, gc.ErrorMatches, `unrecognized args: \["b"\]`)
+}
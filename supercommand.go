@@ -56,12 +56,32 @@ type SuperCommandParams struct {
 	// in the help output.
 	NotifyHelp func([]string)
 
+	// CategoryOrder holds the order in which command categories, as
+	// given by Info.Category, are listed in help. Categories not in
+	// the list follow in alphabetical order.
+	CategoryOrder []string
+
+	// Middleware holds functions that wrap the Init and Run calls of
+	// every subcommand, including the subcommands of nested
+	// SuperCommands. The first middleware is the outermost.
+	Middleware []Middleware
+
 	Name    string
 	Purpose string
 	Doc     string
+	// Category holds the category under which the SuperCommand is
+	// listed when it is a subcommand of another SuperCommand.
+	Category string
+	// Hidden causes the SuperCommand to be left out of the list of
+	// commands of its parent SuperCommand.
+	Hidden bool
 	// Log holds the Log value associated with the supercommand. If it's nil,
 	// no logging flags will be configured.
 	Log *Log
+	// Prompting holds the Prompting value associated with the
+	// supercommand. If it's nil, no --yes or --no-prompt flags will
+	// be configured.
+	Prompting *Prompting
 	// GlobalFlags specifies a value that can add more global flags to the
 	// supercommand which will also be available on all subcommands.
 	GlobalFlags     FlagAdder
@@ -93,11 +113,14 @@ type FlagAdder interface {
 // the fully initialized structure.
 func NewSuperCommand(params SuperCommandParams) *SuperCommand {
 	command := &SuperCommand{
-		Name:    params.Name,
-		Purpose: params.Purpose,
-		Doc:     params.Doc,
-		Log:     params.Log,
-		Aliases: params.Aliases,
+		Name:      params.Name,
+		Purpose:   params.Purpose,
+		Doc:       params.Doc,
+		Category:  params.Category,
+		Hidden:    params.Hidden,
+		Log:       params.Log,
+		Prompting: params.Prompting,
+		Aliases:   params.Aliases,
 
 		globalFlags:         params.GlobalFlags,
 		usagePrefix:         params.UsagePrefix,
@@ -105,6 +128,8 @@ func NewSuperCommand(params SuperCommandParams) *SuperCommand {
 		version:             params.Version,
 		notifyRun:           params.NotifyRun,
 		notifyHelp:          params.NotifyHelp,
+		middleware:          params.Middleware,
+		categoryOrder:       params.CategoryOrder,
 		userAliasesFilename: params.UserAliasesFilename,
 		FlagKnownAs:         params.FlagKnownAs,
 	}
@@ -141,7 +166,10 @@ type SuperCommand struct {
 	Name                string
 	Purpose             string
 	Doc                 string
+	Category            string
+	Hidden              bool
 	Log                 *Log
+	Prompting           *Prompting
 	Aliases             []string
 	globalFlags         FlagAdder
 	version             string
@@ -160,6 +188,18 @@ type SuperCommand struct {
 	missingCallback     MissingCallback
 	notifyRun           func(string)
 	notifyHelp          func([]string)
+	middleware          []Middleware
+	categoryOrder       []string
+	seeAlsoChecked      bool
+	actionArgs          []string
+
+	// parent holds the SuperCommand that c is registered with, if any.
+	parent *SuperCommand
+
+	// parentPath and parentMiddleware are set by the parent
+	// SuperCommand, if any, when c is selected as its subcommand.
+	parentPath       []string
+	parentMiddleware []Middleware
 
 	// FlagKnownAs allows different projects to customise what their flags are
 	// known as, e.g. 'flag', 'option', 'item'. All error/log messages
@@ -187,10 +227,11 @@ func (c *SuperCommand) init() {
 	}
 	c.help.init()
 	c.subcmds = map[string]commandReference{
-		"help": {command: c.help},
+		"help": {name: "help", command: c.help},
 	}
 	if c.version != "" {
 		c.subcmds["version"] = commandReference{
+			name:    "version",
 			command: newVersionCommand(c.version),
 		}
 	}
@@ -297,41 +338,221 @@ func (c *SuperCommand) insert(value commandReference) {
 		panic(fmt.Sprintf("command already registered: %q", value.name))
 	}
 	c.subcmds[value.name] = value
+	if nested, ok := value.command.(*SuperCommand); ok && value.alias == "" {
+		nested.parent = c
+	}
+}
+
+// resolveSeeAlso returns the command line that shows the help for name,
+// a command or help topic of c as given in Info.SeeAlso, such as
+// "cloud add" or "basics". Aliases are replaced by the names of the
+// commands they refer to.
+func (c *SuperCommand) resolveSeeAlso(name string) (string, error) {
+	words := strings.Fields(name)
+	if len(words) == 0 {
+		return "", fmt.Errorf("empty command or topic")
+	}
+	if len(words) == 1 {
+		if _, ok := c.help.topics[name]; ok {
+			return strings.Join(append(c.commandPath(), "help", name), " "), nil
+		}
+	}
+	super := c
+	path := c.commandPath()
+	for i, word := range words {
+		if super == nil {
+			return "", fmt.Errorf("%q is not a SuperCommand", strings.Join(words[:i], " "))
+		}
+		action, ok := super.subcmds[word]
+		if !ok {
+			return "", fmt.Errorf("unknown command or topic %q", name)
+		}
+		if action.alias != "" {
+			word = action.alias
+		}
+		path = append(path, strings.Fields(word)...)
+		super, _ = action.command.(*SuperCommand)
+	}
+	return strings.Join(path, " "), nil
+}
+
+// root returns the outermost SuperCommand that c is registered with,
+// or c itself.
+func (c *SuperCommand) root() *SuperCommand {
+	for c.parent != nil {
+		c = c.parent
+	}
+	return c
+}
+
+// seeAlsoRef resolves name, a reference in the Info.SeeAlso of one of c's
+// subcommands, from the outermost SuperCommand.
+func (c *SuperCommand) seeAlsoRef(name string) (string, error) {
+	return c.root().resolveSeeAlso(name)
 }
 
-// describeCommands returns a short description of each registered subcommand.
-func (c *SuperCommand) describeCommands(simple bool) string {
-	var lineFormat = "    %-*s - %s"
+// CheckSeeAlso returns an error if any of the Info.SeeAlso references of
+// c's subcommands, or of the subcommands of nested SuperCommands, does not
+// name a command or help topic. References are resolved from the
+// outermost SuperCommand, so all the commands must be registered first;
+// the check is made when the outermost SuperCommand is first initialized,
+// and may also be made by tests.
+func (c *SuperCommand) CheckSeeAlso() error {
+	var problems []string
+	c.checkSeeAlso(&problems)
+	if len(problems) == 0 {
+		return nil
+	}
+	sort.Strings(problems)
+	return fmt.Errorf("invalid see also reference in %s", strings.Join(problems, "; "))
+}
+
+// checkSeeAlso adds a description of each invalid see also reference
+// in c's subcommands to problems.
+func (c *SuperCommand) checkSeeAlso(problems *[]string) {
+	for name, action := range c.subcmds {
+		if action.alias != "" {
+			continue
+		}
+		for _, ref := range action.command.Info().SeeAlso {
+			if _, err := c.seeAlsoRef(ref); err != nil {
+				path := strings.Join(append(c.commandPath(), name), " ")
+				*problems = append(*problems, fmt.Sprintf("%s: %v", path, err))
+			}
+		}
+		if nested, ok := action.command.(*SuperCommand); ok {
+			nested.checkSeeAlso(problems)
+		}
+	}
+}
+
+// VisitCommands calls fn for each command registered with c, including
+// the subcommands of nested SuperCommands, in alphabetical order. The
+// path holds the names of the commands that lead to the command, starting
+// with c's name. Aliases are not visited.
+func (c *SuperCommand) VisitCommands(fn func(path []string, command Command)) {
+	c.visitCommands(c.commandPath(), fn)
+}
+
+// visitCommands implements VisitCommands for a SuperCommand reached
+// through the given path.
+func (c *SuperCommand) visitCommands(path []string, fn func(path []string, command Command)) {
+	names := make([]string, 0, len(c.subcmds))
+	for name, action := range c.subcmds {
+		if action.alias == "" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		commandPath := append(path[:len(path):len(path)], name)
+		command := c.subcmds[name].command
+		fn(commandPath, command)
+		if nested, ok := command.(*SuperCommand); ok {
+			nested.visitCommands(commandPath, fn)
+		}
+	}
+}
+
+// uncategorized is the heading under which commands without a category
+// are listed when other commands have one.
+const uncategorized = "Other"
+
+// describeCommands returns a short description of each registered
+// subcommand. If any of the commands has a category, they are grouped
+// under category headings. If category is not empty, only the commands
+// in that category are described.
+func (c *SuperCommand) describeCommands(simple bool, category string) string {
+	var lineFormat = "    %s - %s"
 	var outputFormat = "commands:\n%s"
+	var headingFormat = "\n%s:"
 	if simple {
-		lineFormat = "%-*s  %s"
+		lineFormat = "%s  %s"
 		outputFormat = "%s"
+		headingFormat = "%s:"
 	}
-	cmds := make([]string, len(c.subcmds))
-	i := 0
+	groups := make(map[string][]string)
 	longest := 0
-	for name := range c.subcmds {
-		if len(name) > longest {
-			longest = len(name)
+	for name, action := range c.subcmds {
+		info := action.command.Info()
+		if info.Hidden {
+			continue
+		}
+		cmdCategory := info.Category
+		if category != "" && !strings.EqualFold(cmdCategory, category) {
+			continue
+		}
+		if width := displayWidth(name); width > longest {
+			longest = width
 		}
-		cmds[i] = name
-		i++
-	}
-	sort.Strings(cmds)
-	var result []string
-	for _, name := range cmds {
-		action := c.subcmds[name]
 		if deprecated, _ := action.Deprecated(); deprecated {
 			continue
 		}
-		info := action.command.Info()
-		purpose := info.Purpose
-		if action.alias != "" {
-			purpose = "Alias for '" + action.alias + "'."
+		groups[cmdCategory] = append(groups[cmdCategory], name)
+	}
+	_, grouped := groups[""]
+	grouped = len(groups) > 1 || len(groups) == 1 && !grouped
+	var sections []string
+	for _, cmdCategory := range c.orderCategories(groups) {
+		var result []string
+		if grouped {
+			heading := cmdCategory
+			if heading == "" {
+				heading = uncategorized
+			}
+			result = append(result, fmt.Sprintf(headingFormat, heading))
 		}
-		result = append(result, fmt.Sprintf(lineFormat, longest, name, purpose))
+		cmds := groups[cmdCategory]
+		sort.Strings(cmds)
+		for _, name := range cmds {
+			action := c.subcmds[name]
+			purpose := action.command.Info().Purpose
+			if action.alias != "" {
+				purpose = "Alias for '" + action.alias + "'."
+			}
+			result = append(result, fmt.Sprintf(lineFormat, padRight(name, longest), purpose))
+		}
+		sections = append(sections, strings.Join(result, "\n"))
+	}
+	separator := "\n"
+	if grouped && simple {
+		separator = "\n\n"
 	}
-	return fmt.Sprintf(outputFormat, strings.Join(result, "\n"))
+	return fmt.Sprintf(outputFormat, strings.Join(sections, separator))
+}
+
+// orderCategories returns the categories in groups in the order in which
+// they should be listed: those in c.categoryOrder first, then any others
+// alphabetically, then the commands with no category.
+func (c *SuperCommand) orderCategories(groups map[string][]string) []string {
+	var order []string
+	for _, category := range c.categoryOrder {
+		if _, ok := groups[category]; ok && !containsString(order, category) {
+			order = append(order, category)
+		}
+	}
+	var rest []string
+	for category := range groups {
+		if category != "" && !containsString(order, category) {
+			rest = append(rest, category)
+		}
+	}
+	sort.Strings(rest)
+	order = append(order, rest...)
+	if _, ok := groups[""]; ok {
+		order = append(order, "")
+	}
+	return order
+}
+
+// hasCategory reports whether any subcommand is in the given category.
+func (c *SuperCommand) hasCategory(category string) bool {
+	for _, action := range c.subcmds {
+		if info := action.command.Info(); !info.Hidden && strings.EqualFold(info.Category, category) {
+			return true
+		}
+	}
+	return false
 }
 
 // Info returns a description of the currently selected subcommand, or of the
@@ -347,7 +568,7 @@ func (c *SuperCommand) Info() *Info {
 	if doc := strings.TrimSpace(c.Doc); doc != "" {
 		docParts = append(docParts, doc)
 	}
-	if cmds := c.describeCommands(false); cmds != "" {
+	if cmds := c.describeCommands(false, ""); cmds != "" {
 		docParts = append(docParts, cmds)
 	}
 	return &Info{
@@ -356,6 +577,8 @@ func (c *SuperCommand) Info() *Info {
 		Purpose:     c.Purpose,
 		Doc:         strings.Join(docParts, "\n\n"),
 		Aliases:     c.Aliases,
+		Category:    c.Category,
+		Hidden:      c.Hidden,
 		FlagKnownAs: c.FlagKnownAs,
 	}
 }
@@ -369,6 +592,9 @@ func (c *SuperCommand) SetCommonFlags(f *gnuflag.FlagSet) {
 	if c.Log != nil {
 		c.Log.AddFlags(f)
 	}
+	if c.Prompting != nil {
+		c.Prompting.AddFlags(f)
+	}
 	if c.globalFlags != nil {
 		c.globalFlags.AddFlags(f)
 	}
@@ -412,12 +638,24 @@ func (c *SuperCommand) AllowInterspersedFlags() bool {
 
 // Init initializes the command for running.
 func (c *SuperCommand) Init(args []string) error {
+	if c.parent == nil && !c.seeAlsoChecked {
+		// The command tree is complete by the time it is run, so
+		// this is the first chance to check references across it.
+		// A bad reference only spoils the help, so it is reported
+		// without stopping the command.
+		c.seeAlsoChecked = true
+		if err := c.CheckSeeAlso(); err != nil {
+			logger.Warningf("%v", err)
+		}
+	}
 	if c.showDescription {
 		return CheckEmpty(args)
 	}
 	if len(args) == 0 {
 		c.action = c.subcmds["help"]
-		return c.action.command.Init(args)
+		return c.invoke(InitPhase, nil, args, func() error {
+			return c.action.command.Init(args)
+		})
 	}
 
 	if userAlias, found := c.userAliases[args[0]]; found && !c.noAlias {
@@ -429,6 +667,7 @@ func (c *SuperCommand) Init(args []string) error {
 	if c.action, found = c.subcmds[args[0]]; !found {
 		if c.missingCallback != nil {
 			c.action = commandReference{
+				name: args[0],
 				command: &missingCommand{
 					callback:  c.missingCallback,
 					superName: c.Name,
@@ -436,8 +675,11 @@ func (c *SuperCommand) Init(args []string) error {
 					args:      args[1:],
 				},
 			}
-			// Yes return here, no Init called on missing Command.
-			return nil
+			// No Init is called on a missing command, but the
+			// middleware still sees the init phase.
+			return c.invoke(InitPhase, nil, args[1:], func() error {
+				return nil
+			})
 		}
 		return fmt.Errorf("unrecognized command: %s %s", c.Name, args[0])
 	}
@@ -459,7 +701,9 @@ func (c *SuperCommand) Init(args []string) error {
 		args = []string{c.action.name}
 		c.action = c.subcmds["help"]
 	}
-	return c.action.command.Init(args)
+	return c.invoke(InitPhase, nil, args, func() error {
+		return c.action.command.Init(args)
+	})
 }
 
 // Run executes the subcommand that was selected in Init.
@@ -490,7 +734,17 @@ func (c *SuperCommand) Run(ctx *Context) error {
 	if deprecated, replacement := c.action.Deprecated(); deprecated {
 		ctx.Infof("WARNING: %q is deprecated, please use %q", c.action.name, replacement)
 	}
-	err := c.action.command.Run(ctx)
+	err := c.invoke(RunPhase, ctx, c.actionArgs, func() error {
+		// Flags are resolved inside the middleware, so that it sees
+		// any errors in their values.
+		if c.Prompting != nil {
+			c.Prompting.Start(ctx)
+		}
+		if err := ResolveFlags(ctx, c.commonflags); err != nil {
+			return err
+		}
+		return Run(c.action.command, ctx)
+	})
 	if err != nil && !IsErrSilent(err) {
 		WriteError(ctx.Stderr, err)
 		logger.Debugf("error stack: \n%v", errors.ErrorStack(err))
@@ -512,10 +766,10 @@ type missingCommand struct {
 	args      []string
 }
 
-// Missing commands only need to supply Info for the interface, but this is
-// never called.
+// Info returns the name the missing command was called by, for middleware
+// that reports on it.
 func (c *missingCommand) Info() *Info {
-	return nil
+	return &Info{Name: c.name}
 }
 
 func (c *missingCommand) Run(ctx *Context) error {
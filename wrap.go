@@ -0,0 +1,147 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENSE file for details.
+
+package cmd
+
+import (
+	"os"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+// helpWidth returns the width to which help written to ctx.Stdout should
+// be wrapped. Help is only wrapped when it is written to a terminal whose
+// width is known; otherwise helpWidth returns 0, and the help is left as
+// written.
+func (ctx *Context) helpWidth() int {
+	if f, ok := ctx.Stdout.(*os.File); ok && terminal.IsTerminal(int(f.Fd())) {
+		if width, _, err := terminal.GetSize(int(f.Fd())); err == nil && width > 0 {
+			return width
+		}
+	}
+	return 0
+}
+
+// wideRanges holds the ranges of characters that take up two columns on
+// a terminal, from the Unicode East Asian Width property.
+var wideRanges = []struct{ lo, hi rune }{
+	{0x1100, 0x115f},
+	{0x2e80, 0x303e},
+	{0x3041, 0x33ff},
+	{0x3400, 0x4dbf},
+	{0x4e00, 0x9fff},
+	{0xa000, 0xa4cf},
+	{0xac00, 0xd7a3},
+	{0xf900, 0xfaff},
+	{0xfe30, 0xfe4f},
+	{0xff00, 0xff60},
+	{0xffe0, 0xffe6},
+	{0x1f300, 0x1f64f},
+	{0x1f900, 0x1f9ff},
+	{0x20000, 0x2fffd},
+	{0x30000, 0x3fffd},
+}
+
+// runeWidth returns the number of columns that r takes up on a terminal.
+func runeWidth(r rune) int {
+	if unicode.In(r, unicode.Mn, unicode.Me, unicode.Cf) {
+		return 0
+	}
+	for _, wide := range wideRanges {
+		if r >= wide.lo && r <= wide.hi {
+			return 2
+		}
+	}
+	return 1
+}
+
+// displayWidth returns the number of columns that s takes up on a
+// terminal.
+func displayWidth(s string) int {
+	width := 0
+	for _, r := range s {
+		width += runeWidth(r)
+	}
+	return width
+}
+
+// padRight pads s with spaces to the given display width, as the %-*s
+// verb does for byte counts.
+func padRight(s string, width int) string {
+	if pad := width - displayWidth(s); pad > 0 {
+		return s + strings.Repeat(" ", pad)
+	}
+	return s
+}
+
+// listItemPattern matches the start of an item in a bulleted or numbered
+// list.
+var listItemPattern = regexp.MustCompile(`^([-*+]|\d+[.)])\s+`)
+
+// reflow rewraps the paragraphs and list items in text to the given
+// width. Lines that start with white space, such as code, indented text
+// and the continuation lines of list items, are left alone, as is all of
+// text if width is not positive.
+func reflow(text string, width int) string {
+	if width <= 0 {
+		return text
+	}
+	var out []string
+	var words []string
+	var first, indent string
+	flush := func() {
+		if len(words) > 0 {
+			out = append(out, wrapWords(words, first, indent, width)...)
+		}
+		words = nil
+	}
+	for _, line := range strings.Split(text, "\n") {
+		switch {
+		case strings.TrimSpace(line) == "":
+			flush()
+			out = append(out, "")
+		case strings.TrimLeft(line, " \t") != line:
+			flush()
+			out = append(out, line)
+		case listItemPattern.MatchString(line):
+			flush()
+			first = listItemPattern.FindString(line)
+			indent = strings.Repeat(" ", displayWidth(first))
+			words = strings.Fields(line[len(first):])
+		default:
+			if len(words) == 0 {
+				first, indent = "", ""
+			}
+			words = append(words, strings.Fields(line)...)
+		}
+	}
+	flush()
+	return strings.Join(out, "\n")
+}
+
+// wrapWords arranges words into lines no wider than width where possible.
+// The first line starts with first and the others with indent.
+func wrapWords(words []string, first, indent string, width int) []string {
+	var lines []string
+	line := first
+	lineWidth := displayWidth(first)
+	empty := true
+	for _, word := range words {
+		wordWidth := displayWidth(word)
+		if !empty && lineWidth+1+wordWidth > width {
+			lines = append(lines, line)
+			line, lineWidth, empty = indent, displayWidth(indent), true
+		}
+		if !empty {
+			line += " "
+			lineWidth++
+		}
+		line += word
+		lineWidth += wordWidth
+		empty = false
+	}
+	return append(lines, line)
+}
@@ -0,0 +1,131 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENSE file for details.
+
+package cmd_test
+
+import (
+	"strings"
+
+	"github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/cmd"
+	"github.com/juju/cmd/cmdtesting"
+)
+
+type WrapSuite struct {
+	testing.IsolationSuite
+}
+
+var _ = gc.Suite(&WrapSuite{})
+
+func (s *WrapSuite) TestReflow(c *gc.C) {
+	for i, test := range []struct {
+		about  string
+		text   string
+		width  int
+		expect string
+	}{{
+		about:  "short text is unchanged",
+		text:   "one two three",
+		width:  20,
+		expect: "one two three",
+	}, {
+		about:  "paragraphs are rewrapped",
+		text:   "one two\nthree four five six\n\nseven eight",
+		width:  10,
+		expect: "one two\nthree four\nfive six\n\nseven\neight",
+	}, {
+		about:  "long words are not broken",
+		text:   "a verylongword b",
+		width:  5,
+		expect: "a\nverylongword\nb",
+	}, {
+		about:  "list items keep a hanging indent",
+		text:   "- one two three four\n  five\n10. six seven eight",
+		width:  12,
+		expect: "- one two\n  three four\n  five\n10. six\n    seven\n    eight",
+	}, {
+		about:  "code is left alone",
+		text:   "run this:\n\n    juju deploy --to lxd:0 mysql\n\tjuju status",
+		width:  10,
+		expect: "run this:\n\n    juju deploy --to lxd:0 mysql\n\tjuju status",
+	}, {
+		about:  "indented lines are left alone",
+		text:   "one two three\n  four five six seven\n - eight nine ten",
+		width:  10,
+		expect: "one two\nthree\n  four five six seven\n - eight nine ten",
+	}, {
+		about:  "text is left alone without a width",
+		text:   "one two three four five six seven\n- eight",
+		width:  0,
+		expect: "one two three four five six seven\n- eight",
+	}, {
+		about:  "wide characters count as two columns",
+		text:   "日本語 日本語 日本語",
+		width:  14,
+		expect: "日本語 日本語\n日本語",
+	}} {
+		c.Logf("test %d: %s", i, test.about)
+		c.Check(cmd.Reflow(test.text, test.width), gc.Equals, test.expect)
+	}
+}
+
+func (s *WrapSuite) TestDisplayWidth(c *gc.C) {
+	c.Check(cmd.DisplayWidth("abc"), gc.Equals, 3)
+	c.Check(cmd.DisplayWidth("café"), gc.Equals, 4)
+	c.Check(cmd.DisplayWidth("café"), gc.Equals, 4)
+	c.Check(cmd.DisplayWidth("日本"), gc.Equals, 4)
+}
+
+type docCommand struct {
+	cmd.CommandBase
+	doc string
+}
+
+func (c *docCommand) Info() *cmd.Info {
+	return &cmd.Info{Name: "verb", Doc: c.doc}
+}
+
+func (c *docCommand) Run(ctx *cmd.Context) error {
+	return nil
+}
+
+func (s *WrapSuite) TestHelpIsNotWrapped(c *gc.C) {
+	doc := strings.TrimSpace(strings.Repeat("word ", 30))
+	info := &cmd.Info{
+		Name:    "verb",
+		Purpose: "do something",
+		Doc:     doc,
+	}
+	c.Check(string(info.Help(cmdtesting.NewFlagSet())), gc.Equals, `
+Usage: verb
+
+Summary:
+do something
+
+Details:
+`[1:]+doc+"\n")
+
+	// Nor is help written by Main when stdout is not a terminal.
+	ctx := cmdtesting.Context(c)
+	code := cmd.Main(&docCommand{doc: doc}, ctx, []string{"--help"})
+	c.Assert(code, gc.Equals, 0)
+	c.Check(cmdtesting.Stdout(ctx), jc.Contains, "\n"+doc+"\n")
+}
+
+func (s *WrapSuite) TestCommandsAlignedByDisplayWidth(c *gc.C) {
+	super := cmd.NewSuperCommand(cmd.SuperCommandParams{Name: "jujutsu"})
+	super.Register(&TestCommand{Name: "日本"})
+	super.Register(&TestCommand{Name: "blah"})
+	super.Register(&TestCommand{Name: "x"})
+	ctx := cmdtesting.Context(c)
+	code := cmd.Main(super, ctx, []string{"help", "commands"})
+	c.Assert(code, gc.Equals, 0)
+	c.Assert(cmdtesting.Stdout(ctx), gc.Equals, ""+
+		"blah  blah the juju\n"+
+		"help  Show help on a command or other topic.\n"+
+		"x     x the juju\n"+
+		"日本  日本 the juju\n")
+}